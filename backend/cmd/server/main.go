@@ -42,7 +42,12 @@ func main() {
 	defer redisClient.Close()
 
 	gameHub := hub.New(redisClient)
-	go gameHub.Run()
+	runCtx, stopRun := context.WithCancel(context.Background())
+	hubDone := make(chan struct{})
+	go func() {
+		gameHub.Run(runCtx)
+		close(hubDone)
+	}()
 
 	r := chi.NewRouter()
 	r.Use(middleware.Logger)
@@ -57,6 +62,9 @@ func main() {
 
 	h := handlers.New(database, redisClient, gameHub, cfg)
 	h.RegisterRoutes(r)
+	// Resume games interrupted by a restart and fire pending game timers,
+	// including any that fell due while the server was down.
+	go h.RunEngine(runCtx)
 
 	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -90,5 +98,7 @@ func main() {
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Fatalf("server shutdown failed: %v", err)
 	}
+	stopRun()
+	<-hubDone
 	log.Println("server stopped")
 }
@@ -0,0 +1,255 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/HassanA01/Iftarootv2/backend/internal/models"
+)
+
+// Submission is a player's answer as received over the WebSocket.
+// Which fields are set depends on the question type.
+type Submission struct {
+	QuestionID string
+	OptionID   string   // single_choice, poll
+	OptionIDs  []string // multi_select, ordering (in submitted order)
+	Text       string   // typed_answer; optional for buzzer
+	Number     *float64 // numeric
+}
+
+// validateSubmission checks that sub is well-formed for q and returns the
+// playerAnswer to store. Duplicate option IDs are dropped.
+func validateSubmission(q storedQuestion, sub Submission) (playerAnswer, error) {
+	switch q.Type {
+	case models.QuestionTypeMultiSelect:
+		if len(sub.OptionIDs) == 0 {
+			return playerAnswer{}, fmt.Errorf("option_ids required for %s", q.Type)
+		}
+		seen := make(map[string]bool, len(sub.OptionIDs))
+		ids := make([]string, 0, len(sub.OptionIDs))
+		for _, id := range sub.OptionIDs {
+			if !q.hasOption(id) {
+				return playerAnswer{}, fmt.Errorf("unknown option %s", id)
+			}
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+		return playerAnswer{OptionIDs: ids}, nil
+	case models.QuestionTypeOrdering:
+		if len(sub.OptionIDs) != len(q.Options) {
+			return playerAnswer{}, fmt.Errorf("ordering must list all %d options", len(q.Options))
+		}
+		seen := make(map[string]bool, len(sub.OptionIDs))
+		for _, id := range sub.OptionIDs {
+			if !q.hasOption(id) {
+				return playerAnswer{}, fmt.Errorf("unknown option %s", id)
+			}
+			if seen[id] {
+				return playerAnswer{}, fmt.Errorf("option %s listed twice", id)
+			}
+			seen[id] = true
+		}
+		return playerAnswer{OptionIDs: append([]string(nil), sub.OptionIDs...)}, nil
+	case models.QuestionTypeTypedAnswer:
+		text := strings.TrimSpace(sub.Text)
+		if text == "" {
+			return playerAnswer{}, fmt.Errorf("text required for %s", q.Type)
+		}
+		if r := []rune(text); len(r) > MaxTypedAnswerLength {
+			text = string(r[:MaxTypedAnswerLength])
+		}
+		return playerAnswer{Text: text}, nil
+	case models.QuestionTypeBuzzer:
+		// The buzzing player may answer aloud, so text is optional.
+		text := strings.TrimSpace(sub.Text)
+		if r := []rune(text); len(r) > MaxTypedAnswerLength {
+			text = string(r[:MaxTypedAnswerLength])
+		}
+		return playerAnswer{Text: text}, nil
+	case models.QuestionTypeNumeric:
+		if sub.Number == nil || math.IsNaN(*sub.Number) || math.IsInf(*sub.Number, 0) {
+			return playerAnswer{}, fmt.Errorf("finite number required for %s", q.Type)
+		}
+		n := *sub.Number
+		return playerAnswer{Number: &n}, nil
+	default:
+		if sub.OptionID == "" {
+			return playerAnswer{}, fmt.Errorf("option_id required")
+		}
+		if !q.hasOption(sub.OptionID) {
+			return playerAnswer{}, fmt.Errorf("unknown option %s", sub.OptionID)
+		}
+		return playerAnswer{OptionID: sub.OptionID}, nil
+	}
+}
+
+// evaluateAnswer scores a stored answer against q using q's scoring strategy,
+// scaled by its points multiplier. elapsed is seconds since the question
+// opened. Polls are never scored.
+func evaluateAnswer(q storedQuestion, ans playerAnswer, elapsed float64) (isCorrect bool, points int) {
+	if q.Type == models.QuestionTypePoll {
+		return false, 0
+	}
+	isCorrect, credit := gradeAnswer(q, ans)
+	points = StrategyFor(q.ScoringStrategy).Points(credit, elapsed, q.TimeLimit)
+	return isCorrect, points * q.multiplier()
+}
+
+// multiplier returns the question's points multiplier, defaulting to 1x.
+func (q storedQuestion) multiplier() int {
+	if q.PointsMultiplier == nil {
+		return 1
+	}
+	return *q.PointsMultiplier
+}
+
+// gradeAnswer returns how much of the answer was right as credit in [0, 1].
+// isCorrect is true only for a fully correct answer (or, for numeric
+// questions, a guess within tolerance); credit may still be non-zero for
+// partially correct multi-select and ordering answers.
+func gradeAnswer(q storedQuestion, ans playerAnswer) (isCorrect bool, credit float64) {
+	switch q.Type {
+	case models.QuestionTypeMultiSelect:
+		credit = MultiSelectCredit(ans.OptionIDs, q.correctOptionIDs(), q.PartialCredit)
+		return credit == 1, credit
+	case models.QuestionTypeOrdering:
+		credit = OrderingCredit(ans.OptionIDs, q.correctOrder(), q.PartialCredit)
+		return credit == 1, credit
+	case models.QuestionTypeTypedAnswer:
+		if !MatchTypedAnswer(ans.Text, q.AcceptedAnswers, q.matchRules()) {
+			return false, 0
+		}
+		return true, 1
+	case models.QuestionTypeNumeric:
+		if ans.Number == nil || q.NumericTarget == nil {
+			return false, 0
+		}
+		credit = NumericCredit(*ans.Number, *q.NumericTarget, q.NumericTolerance)
+		return credit > 0, credit
+	case models.QuestionTypeBuzzer:
+		if ans.Judged == nil || !*ans.Judged {
+			return false, 0
+		}
+		return true, 1
+	default:
+		if ans.OptionID != q.correctOptionID() {
+			return false, 0
+		}
+		return true, 1
+	}
+}
+
+// resolveScoringStrategies fills in quizDefault for questions that do not
+// override the quiz's scoring strategy.
+func resolveScoringStrategies(questions []storedQuestion, quizDefault string) {
+	for i := range questions {
+		if questions[i].ScoringStrategy == "" {
+			questions[i].ScoringStrategy = quizDefault
+		}
+	}
+}
+
+// matchRules returns the typed-answer matching rules configured on q.
+func (q storedQuestion) matchRules() TextMatchRules {
+	return TextMatchRules{
+		CaseSensitive:    q.CaseSensitive,
+		IgnoreDiacritics: q.IgnoreDiacritics,
+		MaxDistance:      q.MaxDistance,
+	}
+}
+
+// voteCount is the number of players who picked an option in a poll.
+type voteCount struct {
+	OptionID string `json:"option_id"`
+	Text     string `json:"text"`
+	Count    int    `json:"count"`
+}
+
+// voteDistribution counts votes per option, in display order. Options nobody
+// picked are included with a zero count.
+func voteDistribution(q storedQuestion, picks []string) []voteCount {
+	counts := make(map[string]int, len(q.Options))
+	for _, id := range picks {
+		counts[id]++
+	}
+	dist := make([]voteCount, 0, len(q.Options))
+	for _, opt := range q.Options {
+		dist = append(dist, voteCount{OptionID: opt.ID, Text: opt.Text, Count: counts[opt.ID]})
+	}
+	return dist
+}
+
+// correctOptionID returns the first correct option, or "" if there is none.
+func (q storedQuestion) correctOptionID() string {
+	for _, opt := range q.Options {
+		if opt.IsCorrect {
+			return opt.ID
+		}
+	}
+	return ""
+}
+
+// correctOptionIDs returns every correct option in display order.
+func (q storedQuestion) correctOptionIDs() []string {
+	ids := []string{}
+	for _, opt := range q.Options {
+		if opt.IsCorrect {
+			ids = append(ids, opt.ID)
+		}
+	}
+	return ids
+}
+
+// correctOrder returns option IDs in their correct sequence for ordering questions.
+func (q storedQuestion) correctOrder() []string {
+	opts := append([]storedOption(nil), q.Options...)
+	sort.SliceStable(opts, func(i, j int) bool { return opts[i].Ordinal < opts[j].Ordinal })
+	ids := make([]string, 0, len(opts))
+	for _, opt := range opts {
+		ids = append(ids, opt.ID)
+	}
+	return ids
+}
+
+// shuffleOrderingOptions rearranges the options of every ordering question so
+// players are not shown the answer. The correct sequence is kept in Ordinal.
+func shuffleOrderingOptions(questions []storedQuestion) {
+	for i := range questions {
+		q := &questions[i]
+		if q.Type != models.QuestionTypeOrdering || len(q.Options) < 2 {
+			continue
+		}
+		for {
+			rand.Shuffle(len(q.Options), func(a, b int) { //nolint:gosec // display order, not security sensitive
+				q.Options[a], q.Options[b] = q.Options[b], q.Options[a]
+			})
+			if !slices.Equal(q.optionIDs(), q.correctOrder()) {
+				break
+			}
+		}
+	}
+}
+
+// optionIDs returns option IDs in display order.
+func (q storedQuestion) optionIDs() []string {
+	ids := make([]string, 0, len(q.Options))
+	for _, opt := range q.Options {
+		ids = append(ids, opt.ID)
+	}
+	return ids
+}
+
+func (q storedQuestion) hasOption(id string) bool {
+	for _, opt := range q.Options {
+		if opt.ID == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package game
+
+import (
+	"context"
+	"errors"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Game state is loaded, checked and saved by whichever node or goroutine acts
+// on the game: the timer, a player's answer, the host. Saves are therefore
+// compare-and-set on GameState.Version, so that of two actors that loaded the
+// same state only the first to save wins, and per-player writes made while
+// the game is in a phase only land if the phase is still current.
+
+// errStateChanged is returned when the game state changed between being
+// loaded and being saved.
+var errStateChanged = errors.New("game state changed concurrently")
+
+// maxStateRetries bounds how often an operation that lost a race on the game
+// state is run again.
+const maxStateRetries = 5
+
+// saveStateScript stores ARGV[1] as the game state at KEYS[1] with a TTL of
+// ARGV[3] milliseconds, if the stored state is still at version ARGV[2]. A
+// state at version 0 may only be saved where there is none.
+var saveStateScript = redis.NewScript(`
+local cur = redis.call('GET', KEYS[1])
+if cur then
+	if (cjson.decode(cur).version or 0) ~= tonumber(ARGV[2]) then
+		return 0
+	end
+elseif tonumber(ARGV[2]) ~= 0 then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[3])
+return 1
+`)
+
+// setOnceScript sets field ARGV[2] of hash KEYS[2] to ARGV[3], unless it is
+// already set, provided the game state at KEYS[1] is still at version
+// ARGV[1]. It returns -1 if the state has moved on, else whether the field
+// was set. The hash expires after ARGV[4] milliseconds.
+var setOnceScript = redis.NewScript(`
+local cur = redis.call('GET', KEYS[1])
+if not cur or (cjson.decode(cur).version or 0) ~= tonumber(ARGV[1]) then
+	return -1
+end
+local placed = redis.call('HSETNX', KEYS[2], ARGV[2], ARGV[3])
+redis.call('PEXPIRE', KEYS[2], ARGV[4])
+return placed
+`)
+
+// setOnce records a player's value in the hash at key — an answer or a
+// wager — if they have none there yet and the game is still in the state it
+// was loaded in. It reports whether the value was recorded, and returns
+// errStateChanged if the game has moved on.
+func (e *Engine) setOnce(ctx context.Context, state *GameState, key, playerID string, value any) (bool, error) {
+	placed, err := setOnceScript.Run(ctx, e.redis,
+		[]string{redisKeyState(state.SessionCode), key},
+		state.Version, playerID, value, state.Settings.keyTTL().Milliseconds(),
+	).Int()
+	if err != nil {
+		return false, err
+	}
+	if placed < 0 {
+		return false, errStateChanged
+	}
+	return placed == 1, nil
+}
+
+// retryOnConflict runs fn, and runs it again while it fails because the game
+// state changed under it. fn must load the state afresh each time and must
+// not have acted before its save fails.
+func retryOnConflict(fn func() error) error {
+	var err error
+	for range maxStateRetries {
+		if err = fn(); !errors.Is(err, errStateChanged) {
+			return err
+		}
+	}
+	return err
+}
@@ -0,0 +1,296 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// seedState saves a fresh game in the question phase.
+func seedState(t *testing.T, e *Engine, code string) *GameState {
+	t.Helper()
+	state := &GameState{SessionCode: code, Phase: PhaseQuestion}
+	if err := e.saveState(context.Background(), code, state); err != nil {
+		t.Fatal(err)
+	}
+	return state
+}
+
+func TestSaveStateCompareAndSet(t *testing.T) {
+	e := newRedisEngine(t)
+	ctx := context.Background()
+	seedState(t, e, "111111")
+
+	if err := e.saveState(ctx, "111111", &GameState{SessionCode: "111111"}); !errors.Is(err, errStateChanged) {
+		t.Errorf("saving a new game over an existing one: got %v, want errStateChanged", err)
+	}
+
+	a, _ := e.loadState(ctx, "111111")
+	b, _ := e.loadState(ctx, "111111")
+	a.Phase = PhaseReveal
+	if err := e.saveState(ctx, "111111", a); err != nil {
+		t.Fatal(err)
+	}
+	b.Phase = PhaseLeaderboard
+	if err := e.saveState(ctx, "111111", b); !errors.Is(err, errStateChanged) {
+		t.Errorf("stale save: got %v, want errStateChanged", err)
+	}
+	if b.Version != a.Version-1 {
+		t.Errorf("failed save left version %d, want %d", b.Version, a.Version-1)
+	}
+
+	got, _ := e.loadState(ctx, "111111")
+	if got.Phase != PhaseReveal || got.Version != a.Version {
+		t.Errorf("stored phase %s version %d, want %s version %d", got.Phase, got.Version, PhaseReveal, a.Version)
+	}
+}
+
+func TestSetOnce(t *testing.T) {
+	e := newRedisEngine(t)
+	ctx := context.Background()
+	state := seedState(t, e, "222222")
+	key := redisKeyAnswers("222222", 0)
+
+	if placed, err := e.setOnce(ctx, state, key, "p1", "first"); err != nil || !placed {
+		t.Fatalf("first answer: placed %v, err %v", placed, err)
+	}
+	if placed, err := e.setOnce(ctx, state, key, "p1", "second"); err != nil || placed {
+		t.Errorf("second answer: placed %v, err %v, want not placed", placed, err)
+	}
+	if got, _ := e.redis.HGet(ctx, key, "p1").Result(); got != "first" {
+		t.Errorf("stored answer %q, want the first", got)
+	}
+
+	moved, _ := e.loadState(ctx, "222222")
+	moved.Phase = PhaseReveal
+	if err := e.saveState(ctx, "222222", moved); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.setOnce(ctx, state, key, "p2", "late"); !errors.Is(err, errStateChanged) {
+		t.Errorf("answer after the reveal: got %v, want errStateChanged", err)
+	}
+	if n, _ := e.redis.HLen(ctx, key).Result(); n != 1 {
+		t.Errorf("%d answers stored, want 1", n)
+	}
+}
+
+func TestConcurrentTransitionOnce(t *testing.T) {
+	e := newRedisEngine(t)
+	ctx := context.Background()
+	seedState(t, e, "333333")
+
+	// The timer and every player's all-answered check race to reveal; the
+	// reveal happens once however many try.
+	var reveals atomic.Int32
+	var wg sync.WaitGroup
+	for range 32 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := retryOnConflict(func() error {
+				state, err := e.loadState(ctx, "333333")
+				if err != nil {
+					return err
+				}
+				if state.Phase != PhaseQuestion {
+					return nil
+				}
+				state.Phase = PhaseReveal
+				if err := e.saveState(ctx, "333333", state); err != nil {
+					return err
+				}
+				reveals.Add(1)
+				return nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := reveals.Load(); n != 1 {
+		t.Errorf("revealed %d times, want 1", n)
+	}
+}
+
+func TestConcurrentAnswersAndReveal(t *testing.T) {
+	e := newRedisEngine(t)
+	ctx := context.Background()
+	state := seedState(t, e, "444444")
+	key := redisKeyAnswers("444444", 0)
+
+	// Each player submits several times while the question is revealed
+	// under them. Only one submission per player may land, and none after
+	// the reveal.
+	const players = 20
+	var mu sync.Mutex
+	placed := make(map[string]int)
+	var wg sync.WaitGroup
+	for p := range players {
+		for try := range 5 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				id := fmt.Sprintf("p%d", p)
+				ok, err := e.setOnce(ctx, state, key, id, try)
+				if err != nil && !errors.Is(err, errStateChanged) {
+					t.Error(err)
+					return
+				}
+				if ok {
+					mu.Lock()
+					placed[id]++
+					mu.Unlock()
+				}
+			}()
+		}
+	}
+	var revealed map[string]string
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		st, _ := e.loadState(ctx, "444444")
+		st.Phase = PhaseReveal
+		if err := e.saveState(ctx, "444444", st); err != nil {
+			t.Error(err)
+			return
+		}
+		revealed, _ = e.redis.HGetAll(ctx, key).Result()
+	}()
+	wg.Wait()
+
+	for id, n := range placed {
+		if n != 1 {
+			t.Errorf("%s answered %d times", id, n)
+		}
+	}
+	stored, _ := e.redis.HGetAll(ctx, key).Result()
+	if len(stored) != len(placed) {
+		t.Errorf("%d answers stored, %d reported placed", len(stored), len(placed))
+	}
+	if len(stored) != len(revealed) {
+		t.Errorf("%d answers stored, but the reveal saw %d", len(stored), len(revealed))
+	}
+}
+
+func TestConcurrentSelfPacedSubmits(t *testing.T) {
+	e := newRedisEngine(t)
+	ctx := context.Background()
+	state := &GameState{SessionCode: "555555", Phase: PhaseSelfPaced}
+	shown := playerProgress{Index: 2, QuestionStarted: time.Now(), Streak: 2}
+	if err := e.swapProgress(ctx, state, "p1", playerProgress{}, shown); err != nil {
+		t.Fatal(err)
+	}
+
+	// Duplicate submissions race to mark the question answered and extend
+	// the streak, as submitSelfPaced does before scoring; only one may go on
+	// to score.
+	var scored atomic.Int32
+	var wg sync.WaitGroup
+	for range 16 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := retryOnConflict(func() error {
+				p, err := e.loadProgress(ctx, "555555", "p1")
+				if err != nil || p.Answered {
+					return err
+				}
+				answered := p
+				answered.Answered = true
+				answered.Streak = p.Streak + 1
+				if err := e.swapProgress(ctx, state, "p1", p, answered); err != nil {
+					return err
+				}
+				scored.Add(1)
+				return nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := scored.Load(); n != 1 {
+		t.Errorf("scored %d times, want 1", n)
+	}
+	if p, _ := e.loadProgress(ctx, "555555", "p1"); p.Streak != 3 {
+		t.Errorf("streak = %d, want 3", p.Streak)
+	}
+}
+
+func TestConcurrentSelfPacedAdvance(t *testing.T) {
+	e := newRedisEngine(t)
+	ctx := context.Background()
+	state := &GameState{SessionCode: "666666", Phase: PhaseSelfPaced}
+	answered := playerProgress{Index: 0, QuestionStarted: time.Now(), Answered: true}
+	if err := e.swapProgress(ctx, state, "p1", playerProgress{}, answered); err != nil {
+		t.Fatal(err)
+	}
+
+	// Several advances race past the same answered question, as
+	// advanceSelfPaced does; only one moves on, so the next question is not
+	// skipped.
+	var advanced atomic.Int32
+	var wg sync.WaitGroup
+	for range 16 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := retryOnConflict(func() error {
+				p, err := e.loadProgress(ctx, "666666", "p1")
+				if err != nil || !p.Answered {
+					return err
+				}
+				if err := e.swapProgress(ctx, state, "p1", p, playerProgress{Index: p.Index + 1}); err != nil {
+					return err
+				}
+				advanced.Add(1)
+				return nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := advanced.Load(); n != 1 {
+		t.Errorf("advanced %d times, want 1", n)
+	}
+	if p, _ := e.loadProgress(ctx, "666666", "p1"); p.Index != 1 || p.Answered {
+		t.Errorf("progress = %+v, want the unanswered question 1", p)
+	}
+}
+
+func TestRerunWaitsForScoring(t *testing.T) {
+	e := newRedisEngine(t)
+	ctx := context.Background()
+	state := seedState(t, e, "777777")
+	state.Phase = PhaseReveal
+	if err := e.saveState(ctx, "777777", state); err != nil {
+		t.Fatal(err)
+	}
+
+	// The reveal is on screen but its answers are still being recorded.
+	if _, _, err := e.closeForRerun(ctx, "777777"); err == nil {
+		t.Fatal("re-run allowed before the question was scored")
+	}
+	if got, _ := e.loadState(ctx, "777777"); got.Phase != PhaseReveal {
+		t.Errorf("rejected re-run left phase %s, want %s", got.Phase, PhaseReveal)
+	}
+
+	state.Scored = true
+	if err := e.saveState(ctx, "777777", state); err != nil {
+		t.Fatal(err)
+	}
+	if _, scored, err := e.closeForRerun(ctx, "777777"); err != nil || !scored {
+		t.Errorf("re-run after scoring: scored %v, err %v", scored, err)
+	}
+}
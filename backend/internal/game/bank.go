@@ -0,0 +1,73 @@
+package game
+
+import (
+	"context"
+	"log"
+
+	"github.com/HassanA01/Iftarootv2/backend/internal/models"
+)
+
+// drawBankQuestions resolves a quiz's draw rules into concrete questions from
+// its owner's question bank, in rule order. A question is drawn at most once
+// per session and never duplicates one of the quiz's own questions. A rule
+// that cannot be filled draws what is available.
+func (e *Engine) drawBankQuestions(ctx context.Context, quizID string, existing []storedQuestion) ([]storedQuestion, error) {
+	rules, err := e.loadDrawRules(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	used := make([]string, 0, len(existing))
+	for _, q := range existing {
+		used = append(used, q.ID)
+	}
+	var drawn []storedQuestion
+	for _, rule := range rules {
+		picked, err := e.queryQuestions(ctx,
+			`WHERE quiz_id IS NULL
+			   AND admin_id = (SELECT admin_id FROM quizzes WHERE id = $1)
+			   AND ($2 = '' OR $2 = ANY(tags))
+			   AND ($3 = '' OR difficulty = $3)
+			   AND NOT (id::text = ANY($4::text[]))
+			 ORDER BY random()
+			 LIMIT $5`,
+			quizID, rule.Tag, string(rule.Difficulty), used, rule.Count,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if len(picked) < rule.Count {
+			log.Printf("engine: quiz %s draw rule (tag=%q difficulty=%q) wanted %d questions, bank has %d",
+				quizID, rule.Tag, rule.Difficulty, rule.Count, len(picked))
+		}
+		for _, q := range picked {
+			used = append(used, q.ID)
+		}
+		drawn = append(drawn, picked...)
+	}
+	return drawn, nil
+}
+
+func (e *Engine) loadDrawRules(ctx context.Context, quizID string) ([]models.DrawRule, error) {
+	rows, err := e.db.Query(ctx,
+		`SELECT COALESCE(tag, ''), COALESCE(difficulty, ''), count FROM quiz_draw_rules WHERE quiz_id = $1 ORDER BY position`,
+		quizID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []models.DrawRule
+	for rows.Next() {
+		var r models.DrawRule
+		if err := rows.Scan(&r.Tag, &r.Difficulty, &r.Count); err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+	return rules, rows.Err()
+}
@@ -0,0 +1,316 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/HassanA01/Iftarootv2/backend/internal/hub"
+	"github.com/HassanA01/Iftarootv2/backend/internal/models"
+)
+
+// BuzzerAnswerSeconds is how long the player who buzzed has to answer before
+// the floor waits on the host's ruling.
+const BuzzerAnswerSeconds = 10
+
+// questionOnScreen reports whether the current question is still being
+// played: open for answers or, for buzzer questions, held by a player.
+func (p GamePhase) questionOnScreen() bool {
+	return p == PhaseQuestion || p == PhaseBuzzerFloor || p == PhaseBuzzerJudge
+}
+
+// resetBuzzer clears who holds the floor.
+func (s *GameState) resetBuzzer() {
+	s.BuzzerPlayer = ""
+	s.BuzzedAt = time.Time{}
+	s.BuzzerDeadline = time.Time{}
+	s.BuzzerText = ""
+}
+
+// withoutBuzzerQuestions drops buzzer questions, which need a host to judge
+// them, from self-paced sessions.
+func withoutBuzzerQuestions(questions []storedQuestion, settings GameSettings) []storedQuestion {
+	if settings.Mode != models.SessionModeSelfPaced {
+		return questions
+	}
+	kept := questions[:0]
+	for _, q := range questions {
+		if q.Type != models.QuestionTypeBuzzer {
+			kept = append(kept, q)
+		}
+	}
+	return kept
+}
+
+// Buzz gives playerID the floor on the open buzzer question if nobody else has
+// it. The question clock stops while they answer. Players already judged wrong
+// on this question cannot buzz again.
+func (e *Engine) Buzz(ctx context.Context, sessionCode, playerID string) error {
+	return retryOnConflict(func() error {
+		return e.buzz(ctx, sessionCode, playerID)
+	})
+}
+
+// buzz hands over the floor by saving the state that records it. Of players
+// buzzing together only the first save goes through; the rest find the floor
+// taken when they retry.
+func (e *Engine) buzz(ctx context.Context, sessionCode, playerID string) error {
+	state, err := e.loadState(ctx, sessionCode)
+	if err != nil {
+		return fmt.Errorf("load state: %w", err)
+	}
+	if state.Phase == PhaseBuzzerFloor || state.Phase == PhaseBuzzerJudge {
+		return nil // someone else buzzed first
+	}
+	if state.Phase != PhaseQuestion {
+		return fmt.Errorf("buzzer is not open (current: %s)", state.Phase)
+	}
+	if state.Paused {
+		return fmt.Errorf("question is paused")
+	}
+	if state.IsEliminated(playerID) {
+		return fmt.Errorf("player has been eliminated")
+	}
+	questions, err := e.loadCachedQuestions(ctx, sessionCode)
+	if err != nil {
+		return err
+	}
+	if questions[state.CurrentIndex].Type != models.QuestionTypeBuzzer {
+		return fmt.Errorf("not a buzzer question")
+	}
+	answerKey := redisKeyAnswers(sessionCode, state.CurrentIndex)
+	if judged, _ := e.redis.HExists(ctx, answerKey, playerID).Result(); judged {
+		return fmt.Errorf("player already answered this question")
+	}
+
+	now := time.Now()
+	state.Remaining = max(state.QuestionDeadline.Sub(now), 0)
+	state.QuestionDeadline = time.Time{}
+	state.Phase = PhaseBuzzerFloor
+	state.BuzzerPlayer = playerID
+	state.BuzzedAt = now
+	state.BuzzerDeadline = now.Add(BuzzerAnswerSeconds * time.Second)
+	state.BuzzerText = ""
+	if err := e.saveState(ctx, sessionCode, state); err != nil {
+		return err
+	}
+	e.cancelTimer(ctx, sessionCode)
+
+	var name string
+	_ = e.db.QueryRow(ctx, `SELECT name FROM game_players WHERE id::text = $1`, playerID).Scan(&name)
+	e.hub.Broadcast(sessionCode, hub.Message{
+		Type:    hub.MsgBuzzed,
+		Payload: buildBuzzedPayload(state, name, now),
+	})
+	e.hub.BroadcastToPlayer(sessionCode, playerID, hub.Message{
+		Type:    hub.MsgBuzzerFloor,
+		Payload: buildBuzzedPayload(state, name, now),
+	})
+
+	return e.schedule(ctx, timer{Code: sessionCode, Kind: timerBuzzer, Run: state.QuestionRun, PlayerID: playerID}, state.BuzzerDeadline)
+}
+
+// submitBuzzerAnswer takes the typed answer of the player holding the floor
+// and passes it to the host to judge.
+func (e *Engine) submitBuzzerAnswer(ctx context.Context, state *GameState, playerID string, sub Submission) error {
+	if playerID != state.BuzzerPlayer {
+		return fmt.Errorf("player does not hold the buzzer")
+	}
+	questions, err := e.loadCachedQuestions(ctx, state.SessionCode)
+	if err != nil {
+		return err
+	}
+	q := questions[state.CurrentIndex]
+	if q.ID != sub.QuestionID {
+		return fmt.Errorf("question_id mismatch")
+	}
+	ans, err := validateSubmission(q, sub)
+	if err != nil {
+		return fmt.Errorf("invalid answer: %w", err)
+	}
+
+	state.BuzzerText = ans.Text
+	state.Phase = PhaseBuzzerJudge
+	if err := e.saveState(ctx, state.SessionCode, state); err != nil {
+		return err
+	}
+	e.hub.BroadcastToHost(state.SessionCode, hub.Message{
+		Type:    hub.MsgBuzzerAnswer,
+		Payload: map[string]any{"player_id": playerID, "text": ans.Text, "timed_out": false},
+	})
+	return nil
+}
+
+// closeBuzzerWindow stops taking input from playerID once their answering
+// window has passed; the floor stays theirs until the host judges.
+func (e *Engine) closeBuzzerWindow(ctx context.Context, sessionCode string, run int, playerID string) error {
+	state, err := e.loadState(ctx, sessionCode)
+	if err != nil {
+		return err
+	}
+	if state.Phase != PhaseBuzzerFloor || state.QuestionRun != run || state.BuzzerPlayer != playerID {
+		return nil
+	}
+	state.Phase = PhaseBuzzerJudge
+	if err := e.saveState(ctx, sessionCode, state); err != nil {
+		return err
+	}
+	e.hub.BroadcastToHost(sessionCode, hub.Message{
+		Type:    hub.MsgBuzzerAnswer,
+		Payload: map[string]any{"player_id": playerID, "text": "", "timed_out": true},
+	})
+	return nil
+}
+
+// JudgeAnswer records the host's ruling on the player holding the floor. A
+// correct answer reveals the question. A wrong one locks that player out and
+// reopens the buzzer for everyone else with the time that was left, unless
+// nobody is left to buzz or the time has run out.
+func (e *Engine) JudgeAnswer(ctx context.Context, sessionCode string, correct bool) error {
+	return retryOnConflict(func() error {
+		return e.judgeAnswer(ctx, sessionCode, correct)
+	})
+}
+
+// judgeAnswer records the ruling only while the floor is as loaded. A ruling
+// already recorded, by an earlier attempt that lost the race to save the
+// reopened question or by a concurrent one, stands in place of correct.
+func (e *Engine) judgeAnswer(ctx context.Context, sessionCode string, correct bool) error {
+	state, err := e.loadState(ctx, sessionCode)
+	if err != nil {
+		return err
+	}
+	if state.Phase != PhaseBuzzerFloor && state.Phase != PhaseBuzzerJudge {
+		return fmt.Errorf("no buzzer answer to judge (current: %s)", state.Phase)
+	}
+
+	playerID := state.BuzzerPlayer
+	ans := playerAnswer{Text: state.BuzzerText, Judged: &correct, AnsweredAt: state.BuzzedAt}
+	ansData, err := json.Marshal(ans)
+	if err != nil {
+		return err
+	}
+	answerKey := redisKeyAnswers(sessionCode, state.CurrentIndex)
+	placed, err := e.setOnce(ctx, state, answerKey, playerID, string(ansData))
+	if err != nil {
+		return err
+	}
+	if !placed {
+		raw, err := e.redis.HGet(ctx, answerKey, playerID).Bytes()
+		if err != nil {
+			return err
+		}
+		var ruled playerAnswer
+		if err := json.Unmarshal(raw, &ruled); err != nil {
+			return err
+		}
+		if ruled.Judged != nil {
+			correct = *ruled.Judged
+		}
+	}
+
+	judged := hub.Message{
+		Type: hub.MsgBuzzerJudged,
+		Payload: map[string]any{
+			"question_index": state.CurrentIndex,
+			"player_id":      playerID,
+			"correct":        correct,
+		},
+	}
+	reveal := correct || state.Remaining <= 0
+	if !reveal {
+		eligible, err := e.eligibleBuzzers(ctx, sessionCode, state)
+		if err != nil {
+			return err
+		}
+		reveal = eligible == 0
+	}
+	if reveal {
+		// The ruling is recorded, so the reveal scores it.
+		e.hub.Broadcast(sessionCode, judged)
+		return e.triggerReveal(ctx, sessionCode)
+	}
+
+	// Reopen with the clock where it stopped. Time spent on the floor does
+	// not count against the next buzzer's speed.
+	now := time.Now()
+	state.PausedFor += now.Sub(state.BuzzedAt)
+	state.QuestionDeadline = now.Add(state.Remaining)
+	state.Remaining = 0
+	state.Phase = PhaseQuestion
+	state.resetBuzzer()
+	if err := e.saveState(ctx, sessionCode, state); err != nil {
+		return err
+	}
+
+	e.hub.Broadcast(sessionCode, judged)
+	locked, _ := e.redis.HKeys(ctx, answerKey).Result()
+	e.hub.Broadcast(sessionCode, hub.Message{
+		Type: hub.MsgBuzzerReopened,
+		Payload: map[string]any{
+			"question_index": state.CurrentIndex,
+			"locked_out":     locked,
+		},
+	})
+	e.startTimer(ctx, sessionCode, state.CurrentIndex, state.QuestionDeadline.Sub(now))
+	e.broadcastTimer(sessionCode, state)
+	return nil
+}
+
+// eligibleBuzzers counts connected players still in the game who have not
+// been judged on the current question.
+func (e *Engine) eligibleBuzzers(ctx context.Context, sessionCode string, state *GameState) (int, error) {
+	judged, err := e.redis.HKeys(ctx, redisKeyAnswers(sessionCode, state.CurrentIndex)).Result()
+	if err != nil {
+		return 0, err
+	}
+	out := make(map[string]bool, len(judged))
+	for _, id := range judged {
+		out[id] = true
+	}
+	count := 0
+	for _, id := range e.hub.RoomPlayerIDs(sessionCode) {
+		if !state.IsEliminated(id) && !out[id] {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// BuzzerMessage returns who holds the floor for a client joining while a
+// buzzer question is held, or nil if nobody does. The player holding it gets
+// buzzer_floor; the host also sees any answer they have entered.
+func (e *Engine) BuzzerMessage(ctx context.Context, sessionCode, clientID string, isHost bool) (*hub.Message, error) {
+	state, err := e.loadState(ctx, sessionCode)
+	if err != nil {
+		return nil, err
+	}
+	if state.Phase != PhaseBuzzerFloor && state.Phase != PhaseBuzzerJudge {
+		return nil, nil
+	}
+	var name string
+	_ = e.db.QueryRow(ctx, `SELECT name FROM game_players WHERE id::text = $1`, state.BuzzerPlayer).Scan(&name)
+	payload := buildBuzzedPayload(state, name, time.Now())
+	msgType := hub.MsgBuzzed
+	switch {
+	case isHost:
+		payload["text"] = state.BuzzerText
+	case clientID == state.BuzzerPlayer:
+		msgType = hub.MsgBuzzerFloor
+	}
+	return &hub.Message{Type: msgType, Payload: payload}, nil
+}
+
+// buildBuzzedPayload describes who holds the floor and how long they have
+// left to answer at now.
+func buildBuzzedPayload(state *GameState, name string, now time.Time) map[string]any {
+	return map[string]any{
+		"question_index":  state.CurrentIndex,
+		"player_id":       state.BuzzerPlayer,
+		"name":            name,
+		"answer_deadline": state.BuzzerDeadline,
+		"remaining_ms":    max(state.BuzzerDeadline.Sub(now), 0).Milliseconds(),
+		"judging":         state.Phase == PhaseBuzzerJudge,
+	}
+}
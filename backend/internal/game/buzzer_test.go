@@ -0,0 +1,85 @@
+package game
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HassanA01/Iftarootv2/backend/internal/models"
+)
+
+func TestBuzzerSubmissionAndScoring(t *testing.T) {
+	q := storedQuestion{ID: "q", Type: models.QuestionTypeBuzzer, TimeLimit: 20}
+
+	ans, err := validateSubmission(q, Submission{QuestionID: "q"})
+	if err != nil {
+		t.Fatalf("buzzer answers may be spoken, text is optional: %v", err)
+	}
+	if ans.Text != "" {
+		t.Errorf("text = %q, want empty", ans.Text)
+	}
+	ans, _ = validateSubmission(q, Submission{QuestionID: "q", Text: "  " + strings.Repeat("a", MaxTypedAnswerLength+5)})
+	if len([]rune(ans.Text)) != MaxTypedAnswerLength {
+		t.Errorf("text should be trimmed and capped, got %d runes", len([]rune(ans.Text)))
+	}
+
+	yes, no := true, false
+	if correct, points := evaluateAnswer(q, playerAnswer{}, 0); correct || points != 0 {
+		t.Errorf("unjudged answer scored: correct=%v points=%d", correct, points)
+	}
+	if correct, points := evaluateAnswer(q, playerAnswer{Judged: &no}, 0); correct || points != 0 {
+		t.Errorf("answer judged wrong scored: correct=%v points=%d", correct, points)
+	}
+	if correct, points := evaluateAnswer(q, playerAnswer{Judged: &yes}, 0); !correct || points != BasePoints {
+		t.Errorf("answer judged correct: got correct=%v points=%d, want true %d", correct, points, BasePoints)
+	}
+}
+
+func TestWithoutBuzzerQuestions(t *testing.T) {
+	questions := func() []storedQuestion {
+		return []storedQuestion{
+			{ID: "a", Type: models.QuestionTypeSingleChoice},
+			{ID: "b", Type: models.QuestionTypeBuzzer},
+			{ID: "c", Type: models.QuestionTypeTypedAnswer},
+		}
+	}
+
+	live := withoutBuzzerQuestions(questions(), GameSettings{Mode: models.SessionModeLive})
+	if len(live) != 3 {
+		t.Errorf("live sessions keep buzzer questions, got %d questions", len(live))
+	}
+	selfPaced := withoutBuzzerQuestions(questions(), GameSettings{Mode: models.SessionModeSelfPaced})
+	if len(selfPaced) != 2 || selfPaced[0].ID != "a" || selfPaced[1].ID != "c" {
+		t.Errorf("self-paced sessions drop buzzer questions, got %+v", selfPaced)
+	}
+}
+
+func TestBuzzerPhases(t *testing.T) {
+	for _, p := range []GamePhase{PhaseQuestion, PhaseBuzzerFloor, PhaseBuzzerJudge} {
+		if !p.questionOnScreen() {
+			t.Errorf("%s should keep the question on screen", p)
+		}
+	}
+	for _, p := range []GamePhase{PhaseWager, PhaseReveal, PhaseLeaderboard, PhaseSelfPaced} {
+		if p.questionOnScreen() {
+			t.Errorf("%s should not count as a question on screen", p)
+		}
+	}
+
+	now := time.Now()
+	state := &GameState{
+		CurrentIndex:   1,
+		Phase:          PhaseBuzzerFloor,
+		BuzzerPlayer:   "p1",
+		BuzzedAt:       now,
+		BuzzerDeadline: now.Add(4 * time.Second),
+	}
+	payload := buildBuzzedPayload(state, "Aisha", now)
+	if payload["player_id"] != "p1" || payload["remaining_ms"] != int64(4000) || payload["judging"] != false {
+		t.Errorf("unexpected payload %v", payload)
+	}
+	state.resetBuzzer()
+	if state.BuzzerPlayer != "" || !state.BuzzedAt.IsZero() {
+		t.Errorf("resetBuzzer left %+v", state)
+	}
+}
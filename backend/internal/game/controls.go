@@ -0,0 +1,228 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/HassanA01/Iftarootv2/backend/internal/hub"
+	"github.com/HassanA01/Iftarootv2/backend/internal/models"
+)
+
+// EndQuestionNow closes the open question early and reveals it as if the
+// timer had run out. During a wager question's wagering phase it closes
+// wagering instead and opens the question; during sudden death it settles
+// the tie on the answers given so far.
+func (e *Engine) EndQuestionNow(ctx context.Context, sessionCode string) error {
+	return retryOnConflict(func() error {
+		return e.endQuestionNow(ctx, sessionCode)
+	})
+}
+
+func (e *Engine) endQuestionNow(ctx context.Context, sessionCode string) error {
+	state, err := e.loadState(ctx, sessionCode)
+	if err != nil {
+		return err
+	}
+	if state.Phase == PhaseWager {
+		return e.closeWager(ctx, sessionCode, state.QuestionRun)
+	}
+	if state.Phase == PhaseSuddenDeath {
+		return e.resolveSuddenDeath(ctx, sessionCode, state.QuestionRun)
+	}
+	if !state.Phase.questionOnScreen() {
+		return fmt.Errorf("no open question to end (current: %s)", state.Phase)
+	}
+	return e.triggerReveal(ctx, sessionCode)
+}
+
+// SkipQuestion discards the open question without scoring it and moves on to
+// the next question, the intermission if it ended a round, or the podium if
+// it was the last.
+func (e *Engine) SkipQuestion(ctx context.Context, sessionCode string) error {
+	var state *GameState
+	if err := retryOnConflict(func() (err error) {
+		state, err = e.closeSkipped(ctx, sessionCode)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	e.cancelTimer(ctx, sessionCode)
+	e.redis.Del(ctx, redisKeyAnswers(sessionCode, state.CurrentIndex))
+	e.redis.Del(ctx, redisKeyWagers(sessionCode, state.CurrentIndex))
+
+	e.hub.Broadcast(sessionCode, hub.Message{
+		Type:    hub.MsgQuestionSkipped,
+		Payload: map[string]any{"question_index": state.CurrentIndex},
+	})
+
+	next := state.CurrentIndex + 1
+	if next >= state.TotalQuestions {
+		return e.finishGame(ctx, sessionCode, state)
+	}
+	if state.startsRound(next) {
+		return e.openIntermission(ctx, sessionCode, state)
+	}
+	return e.broadcastQuestion(ctx, sessionCode, next)
+}
+
+// closeSkipped marks the open question skipped and leaves the question phase,
+// before its answers are cleared, so a late answer or a timer that already
+// fired cannot reveal it.
+func (e *Engine) closeSkipped(ctx context.Context, sessionCode string) (*GameState, error) {
+	state, err := e.loadState(ctx, sessionCode)
+	if err != nil {
+		return nil, err
+	}
+	if !state.Phase.questionOnScreen() && state.Phase != PhaseWager {
+		return nil, fmt.Errorf("can only skip an open question (current: %s)", state.Phase)
+	}
+	if !slices.Contains(state.Skipped, state.CurrentIndex) {
+		state.Skipped = append(state.Skipped, state.CurrentIndex)
+	}
+	state.Phase = PhaseLeaderboard
+	if err := e.saveState(ctx, sessionCode, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// RerunQuestion reopens the current question from scratch. If it has already
+// been revealed, the points and streak changes it caused are undone first.
+// Best streaks are left as they are.
+func (e *Engine) RerunQuestion(ctx context.Context, sessionCode string) error {
+	var state *GameState
+	var scored bool
+	if err := retryOnConflict(func() (err error) {
+		state, scored, err = e.closeForRerun(ctx, sessionCode)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	if scored {
+		if err := e.undoReveal(ctx, sessionCode, state); err != nil {
+			return err
+		}
+	} else {
+		e.cancelTimer(ctx, sessionCode)
+	}
+	e.redis.Del(ctx, redisKeyAnswers(sessionCode, state.CurrentIndex))
+
+	e.hub.Broadcast(sessionCode, hub.Message{
+		Type:    hub.MsgQuestionRestarted,
+		Payload: map[string]any{"question_index": state.CurrentIndex},
+	})
+	return e.broadcastQuestion(ctx, sessionCode, state.CurrentIndex)
+}
+
+// closeForRerun closes the current question before its answers are cleared,
+// as in SkipQuestion, and reports whether it had been scored. Players
+// eliminated on a scored question are brought back and lives restored in the
+// saved state; the scores themselves are undone afterwards by undoReveal,
+// which uses the snapshots the state keeps. Should that fail, re-running again
+// from the leaderboard undoes the question once more from the same snapshots.
+// A question still being scored cannot be re-run until its reveal is done.
+func (e *Engine) closeForRerun(ctx context.Context, sessionCode string) (state *GameState, scored bool, err error) {
+	state, err = e.loadState(ctx, sessionCode)
+	if err != nil {
+		return nil, false, err
+	}
+	switch state.Phase {
+	case PhaseWager, PhaseQuestion, PhaseBuzzerFloor, PhaseBuzzerJudge:
+	case PhaseReveal, PhaseLeaderboard:
+		scored = !slices.Contains(state.Skipped, state.CurrentIndex)
+		if scored && !state.Scored {
+			return nil, false, fmt.Errorf("question is still being scored")
+		}
+	default:
+		return nil, false, fmt.Errorf("no question to re-run (current: %s)", state.Phase)
+	}
+
+	if scored {
+		maps.DeleteFunc(state.Eliminated, func(_ string, idx int) bool { return idx == state.CurrentIndex })
+		state.Lives = maps.Clone(state.LivesBefore)
+	}
+	state.Skipped = slices.DeleteFunc(state.Skipped, func(i int) bool { return i == state.CurrentIndex })
+	state.Phase = PhaseLeaderboard
+	if err := e.saveState(ctx, sessionCode, state); err != nil {
+		return nil, false, err
+	}
+	return state, scored, nil
+}
+
+// undoReveal reverses the scoring of the current question in the database:
+// answers are deleted, their points subtracted, streaks and lives restored to
+// their snapshots and players eliminated on it brought back.
+func (e *Engine) undoReveal(ctx context.Context, sessionCode string, state *GameState) error {
+	questions, err := e.loadCachedQuestions(ctx, sessionCode)
+	if err != nil {
+		return err
+	}
+	questionID := questions[state.CurrentIndex].ID
+
+	tx, err := e.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE game_players p SET score = p.score - a.points
+		 FROM game_answers a
+		 WHERE a.player_id = p.id AND a.session_id = $1 AND a.question_id = $2`,
+		state.SessionID, questionID,
+	); err != nil {
+		return fmt.Errorf("revert scores: %w", err)
+	}
+	if _, err := tx.Exec(ctx,
+		`DELETE FROM game_answers WHERE session_id = $1 AND question_id = $2`,
+		state.SessionID, questionID,
+	); err != nil {
+		return fmt.Errorf("delete answers: %w", err)
+	}
+	if _, err := tx.Exec(ctx,
+		`UPDATE game_players SET streak = 0 WHERE session_id = $1`, state.SessionID,
+	); err != nil {
+		return fmt.Errorf("reset streaks: %w", err)
+	}
+	for playerID, streak := range state.StreaksBefore {
+		if _, err := tx.Exec(ctx,
+			`UPDATE game_players SET streak = $1 WHERE id::text = $2 AND session_id = $3`,
+			streak, playerID, state.SessionID,
+		); err != nil {
+			return fmt.Errorf("restore streak: %w", err)
+		}
+	}
+	if _, err := tx.Exec(ctx,
+		`UPDATE game_players SET eliminated_at = NULL, eliminated_on = NULL
+		 WHERE session_id = $1 AND eliminated_on = $2`,
+		state.SessionID, state.CurrentIndex,
+	); err != nil {
+		return fmt.Errorf("restore eliminated players: %w", err)
+	}
+	if state.Settings.Elimination == models.EliminationLives {
+		if _, err := tx.Exec(ctx,
+			`UPDATE game_players SET lives = $1 WHERE session_id = $2`, state.Settings.Lives, state.SessionID,
+		); err != nil {
+			return fmt.Errorf("reset lives: %w", err)
+		}
+		for playerID, lives := range state.LivesBefore {
+			if _, err := tx.Exec(ctx,
+				`UPDATE game_players SET lives = $1 WHERE id::text = $2 AND session_id = $3`,
+				lives, playerID, state.SessionID,
+			); err != nil {
+				return fmt.Errorf("restore lives: %w", err)
+			}
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	e.redis.Del(ctx, redisKeyStreaks(sessionCode))
+	e.saveStreaks(ctx, sessionCode, state.StreaksBefore, state.Settings.keyTTL())
+	return nil
+}
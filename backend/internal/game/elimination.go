@@ -0,0 +1,271 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sort"
+
+	"github.com/HassanA01/Iftarootv2/backend/internal/hub"
+	"github.com/HassanA01/Iftarootv2/backend/internal/models"
+)
+
+// survivorResult is how a surviving player fared on a question, used to
+// decide eliminations.
+type survivorResult struct {
+	PlayerID   string
+	Answered   bool
+	IsCorrect  bool
+	TotalScore int
+}
+
+// eliminationsEnabled reports whether players can be knocked out.
+func (g GameSettings) eliminationsEnabled() bool {
+	return g.Elimination != "" && g.Elimination != models.EliminationOff
+}
+
+// IsEliminated reports whether the player has been knocked out.
+func (s *GameState) IsEliminated(playerID string) bool {
+	_, out := s.Eliminated[playerID]
+	return out
+}
+
+// connectedSurvivors counts connected players who have not been eliminated;
+// these are the players an early reveal waits for.
+func (e *Engine) connectedSurvivors(sessionCode string, state *GameState) int {
+	count := 0
+	for _, id := range e.hub.RoomPlayerIDs(sessionCode) {
+		if !state.IsEliminated(id) {
+			count++
+		}
+	}
+	return count
+}
+
+// selectEliminations picks which survivors go out under mode. Someone always
+// survives: if every survivor would be eliminated, nobody is. In bottom mode
+// players tied at the cut-off are all spared rather than split arbitrarily.
+func selectEliminations(mode models.EliminationMode, count int, survivors []survivorResult) []string {
+	var out []string
+	switch mode {
+	case models.EliminationWrongAnswer:
+		for _, s := range survivors {
+			if !s.Answered || !s.IsCorrect {
+				out = append(out, s.PlayerID)
+			}
+		}
+	case models.EliminationBottom:
+		n := min(count, len(survivors)-1)
+		if n <= 0 {
+			return nil
+		}
+		ranked := make([]survivorResult, len(survivors))
+		copy(ranked, survivors)
+		sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].TotalScore < ranked[j].TotalScore })
+		cutoff := ranked[n-1].TotalScore
+		if ranked[n].TotalScore == cutoff {
+			// The tie spans the cut-off; eliminate only those strictly below it.
+			for n > 0 && ranked[n-1].TotalScore == cutoff {
+				n--
+			}
+		}
+		for _, s := range ranked[:n] {
+			out = append(out, s.PlayerID)
+		}
+	}
+	if len(out) >= len(survivors) {
+		return nil
+	}
+	sort.Strings(out)
+	return out
+}
+
+// eliminationResult is what a question did to the players still in the game.
+type eliminationResult struct {
+	Eliminated []string
+	LivesLost  []string       // lives mode only
+	Lives      map[string]int // lives mode only: lives left per surviving player
+}
+
+// applyEliminations knocks out players after the current question has been
+// scored, records it in state and then the DB, and tells each eliminated
+// player. The state is saved first, retrying on a concurrent save, and state
+// is updated to what was saved. Applying them again for the same reveal gives
+// the same result. Polls never eliminate anyone or cost lives.
+func (e *Engine) applyEliminations(ctx context.Context, state *GameState, q storedQuestion, scores map[string]revealScoreEntry) (eliminationResult, error) {
+	var result eliminationResult
+	if !state.Settings.eliminationsEnabled() {
+		return result, nil
+	}
+	livesMode := state.Settings.Elimination == models.EliminationLives
+	if q.Type == models.QuestionTypePoll && !livesMode {
+		return result, nil
+	}
+
+	rows, err := e.db.Query(ctx,
+		`SELECT id::text, score FROM game_players
+		 WHERE session_id = $1 AND (eliminated_at IS NULL OR eliminated_on = $2)`,
+		state.SessionID, state.CurrentIndex,
+	)
+	if err != nil {
+		return result, err
+	}
+	var survivors []survivorResult
+	for rows.Next() {
+		var s survivorResult
+		if err := rows.Scan(&s.PlayerID, &s.TotalScore); err != nil {
+			rows.Close()
+			return result, err
+		}
+		entry, answered := scores[s.PlayerID]
+		s.Answered, s.IsCorrect = answered, entry.IsCorrect
+		survivors = append(survivors, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return result, err
+	}
+
+	if err := retryOnConflict(func() error {
+		fresh, err := e.loadState(ctx, state.SessionCode)
+		if err != nil {
+			return err
+		}
+		if fresh.Phase != PhaseReveal || fresh.QuestionRun != state.QuestionRun {
+			return fmt.Errorf("question %d is no longer being revealed", state.CurrentIndex)
+		}
+		// Decide from the snapshot taken when the reveal began, in case an
+		// earlier attempt got as far as saving.
+		fresh.Lives = maps.Clone(fresh.LivesBefore)
+		maps.DeleteFunc(fresh.Eliminated, func(_ string, idx int) bool { return idx == fresh.CurrentIndex })
+		result = decideEliminations(fresh, q, survivors)
+		if len(result.Eliminated) > 0 || len(result.LivesLost) > 0 {
+			if err := e.saveState(ctx, fresh.SessionCode, fresh); err != nil {
+				return err
+			}
+		}
+		*state = *fresh
+		return nil
+	}); err != nil {
+		return result, err
+	}
+
+	if err := e.saveLives(ctx, state, result.LivesLost); err != nil {
+		return result, err
+	}
+	if len(result.Eliminated) == 0 {
+		return result, nil
+	}
+	if _, err := e.db.Exec(ctx,
+		`UPDATE game_players SET eliminated_at = NOW(), eliminated_on = $1
+		 WHERE session_id = $2 AND id::text = ANY($3::text[])`,
+		state.CurrentIndex, state.SessionID, result.Eliminated,
+	); err != nil {
+		return result, err
+	}
+
+	for _, id := range result.Eliminated {
+		e.hub.BroadcastToPlayer(state.SessionCode, id, hub.Message{
+			Type:    hub.MsgEliminated,
+			Payload: map[string]any{"question_index": state.CurrentIndex},
+		})
+	}
+	return result, nil
+}
+
+// decideEliminations works out what the current question does to survivors
+// and records it in state's lives and eliminations.
+func decideEliminations(state *GameState, q storedQuestion, survivors []survivorResult) eliminationResult {
+	var result eliminationResult
+	if state.Settings.Elimination == models.EliminationLives {
+		if q.Type != models.QuestionTypePoll {
+			if state.Lives == nil {
+				state.Lives = make(map[string]int)
+			}
+			result.LivesLost, result.Eliminated = loseLives(survivors, state.Lives, state.Settings.Lives)
+		}
+		result.Lives = make(map[string]int, len(survivors))
+		for _, s := range survivors {
+			result.Lives[s.PlayerID] = state.livesLeft(s.PlayerID)
+		}
+	} else {
+		result.Eliminated = selectEliminations(state.Settings.Elimination, state.Settings.EliminationCount, survivors)
+	}
+	if len(result.Eliminated) > 0 && state.Eliminated == nil {
+		state.Eliminated = make(map[string]int)
+	}
+	for _, id := range result.Eliminated {
+		state.Eliminated[id] = state.CurrentIndex
+	}
+	return result
+}
+
+// livesLeft returns the player's remaining lives in lives mode.
+func (s *GameState) livesLeft(playerID string) int {
+	if n, ok := s.Lives[playerID]; ok {
+		return n
+	}
+	return s.Settings.Lives
+}
+
+// loseLives takes a life from every survivor who missed the question or got it
+// wrong, updating lives (players missing from it have start lives). It returns
+// who lost a life and who has none left. As with selectEliminations someone
+// always survives: if every survivor would be out, nobody loses a life.
+func loseLives(survivors []survivorResult, lives map[string]int, start int) (lost, out []string) {
+	next := make(map[string]int)
+	for _, s := range survivors {
+		if s.Answered && s.IsCorrect {
+			continue
+		}
+		left, ok := lives[s.PlayerID]
+		if !ok {
+			left = start
+		}
+		next[s.PlayerID] = left - 1
+		lost = append(lost, s.PlayerID)
+		if left-1 <= 0 {
+			out = append(out, s.PlayerID)
+		}
+	}
+	if len(survivors) > 0 && len(out) >= len(survivors) {
+		return nil, nil
+	}
+	maps.Copy(lives, next)
+	sort.Strings(lost)
+	sort.Strings(out)
+	return lost, out
+}
+
+// saveLives persists the lives left by the given players to the DB.
+func (e *Engine) saveLives(ctx context.Context, state *GameState, playerIDs []string) error {
+	if len(playerIDs) == 0 {
+		return nil
+	}
+	lives := make([]int, len(playerIDs))
+	for i, id := range playerIDs {
+		lives[i] = state.livesLeft(id)
+	}
+	_, err := e.db.Exec(ctx,
+		`UPDATE game_players p SET lives = v.lives
+		 FROM unnest($1::text[], $2::int[]) AS v(id, lives)
+		 WHERE p.id::text = v.id AND p.session_id = $3`,
+		playerIDs, lives, state.SessionID,
+	)
+	return err
+}
+
+// lastPlayerStanding reports whether elimination has left at most one player.
+func (e *Engine) lastPlayerStanding(ctx context.Context, state *GameState) bool {
+	if !state.Settings.eliminationsEnabled() {
+		return false
+	}
+	var alive int
+	if err := e.db.QueryRow(ctx,
+		`SELECT COUNT(*) FROM game_players WHERE session_id = $1 AND eliminated_at IS NULL`,
+		state.SessionID,
+	).Scan(&alive); err != nil {
+		return false
+	}
+	return alive <= 1
+}
@@ -5,7 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"sync"
+	"maps"
+	"math"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -20,67 +23,207 @@ import (
 type GamePhase string
 
 const (
-	PhaseStarting    GamePhase = "starting"      // post-start, waiting for host to reconnect
-	PhaseQuestion    GamePhase = "question_open" // question active, accepting answers
-	PhaseReveal      GamePhase = "answer_reveal" // showing correct answer
-	PhaseLeaderboard GamePhase = "leaderboard"   // leaderboard between questions
-	PhaseGameOver    GamePhase = "game_over"     // final podium
+	PhaseStarting     GamePhase = "starting"      // post-start, waiting for host to reconnect
+	PhaseWager        GamePhase = "wager_open"    // wager question: collecting stakes before options appear
+	PhaseQuestion     GamePhase = "question_open" // question active, accepting answers
+	PhaseBuzzerFloor  GamePhase = "buzzer_floor"  // buzzer question: one player has buzzed and is answering
+	PhaseBuzzerJudge  GamePhase = "buzzer_judge"  // buzzer question: waiting for the host to judge
+	PhaseReveal       GamePhase = "answer_reveal" // showing correct answer
+	PhaseLeaderboard  GamePhase = "leaderboard"   // leaderboard between questions
+	PhaseIntermission GamePhase = "intermission"  // between rounds, showing round standings
+	PhaseSuddenDeath  GamePhase = "sudden_death"  // podium tie: tied players answer a reserve question
+	PhaseGameOver     GamePhase = "game_over"     // final podium
+	PhaseSelfPaced    GamePhase = "self_paced"    // window open, players progress individually
 )
 
 // GameState is persisted in Redis for session recovery.
 type GameState struct {
-	SessionCode     string    `json:"session_code"`
-	SessionID       string    `json:"session_id"`
-	CurrentIndex    int       `json:"current_index"`
-	TotalQuestions  int       `json:"total_questions"`
-	Phase           GamePhase `json:"phase"`
-	QuestionStarted time.Time `json:"question_started"`
+	SessionCode     string       `json:"session_code"`
+	SessionID       string       `json:"session_id"`
+	CurrentIndex    int          `json:"current_index"`
+	TotalQuestions  int          `json:"total_questions"`
+	Phase           GamePhase    `json:"phase"`
+	QuestionStarted time.Time    `json:"question_started"`
+	Settings        GameSettings `json:"settings"`
+
+	// Question clock. While paused, QuestionDeadline is zero and Remaining
+	// holds the time left; PausedFor accumulates paused time so it does not
+	// count against answer speed. ExtraSeconds is time added by the host.
+	QuestionDeadline time.Time     `json:"question_deadline"`
+	Paused           bool          `json:"paused"`
+	PausedAt         time.Time     `json:"paused_at"`
+	PausedFor        time.Duration `json:"paused_for"`
+	Remaining        time.Duration `json:"remaining"`
+	ExtraSeconds     int           `json:"extra_seconds"`
+	// WagerDeadline is when the wagering phase of a wager question closes.
+	WagerDeadline time.Time `json:"wager_deadline,omitempty"`
+
+	// Buzzer questions: who holds the floor, when they buzzed, when their
+	// answering window closes and what they entered, if anything.
+	BuzzerPlayer   string    `json:"buzzer_player,omitempty"`
+	BuzzedAt       time.Time `json:"buzzed_at,omitempty"`
+	BuzzerDeadline time.Time `json:"buzzer_deadline,omitempty"`
+	BuzzerText     string    `json:"buzzer_text,omitempty"`
+
+	// QuestionRun increments every time a question opens, so delayed work
+	// from an earlier run of the same index (e.g. a re-run) can tell it is stale.
+	QuestionRun int `json:"question_run"`
+	// Scored is set once the question on reveal has been fully scored; until
+	// then its answers may still be going into the database.
+	Scored bool `json:"scored,omitempty"`
+	// StreaksBefore snapshots streaks as they stood before the last reveal,
+	// so a re-run can restore them.
+	StreaksBefore map[string]int `json:"streaks_before,omitempty"`
+	Skipped       []int          `json:"skipped,omitempty"`    // indices skipped by the host
+	Eliminated    map[string]int `json:"eliminated,omitempty"` // player ID -> question index they went out on
+	// Lives holds lives left by player ID in lives mode; players not in it
+	// still have all Settings.Lives. LivesBefore snapshots it before the last
+	// reveal, like StreaksBefore.
+	Lives       map[string]int `json:"lives,omitempty"`
+	LivesBefore map[string]int `json:"lives_before,omitempty"`
+
+	// Rounds splits the questions into rounds; empty for single-round games.
+	Rounds []RoundInfo `json:"rounds,omitempty"`
+
+	// Sudden death: reserve questions asked so far, and the tied players
+	// answering the current one (nil once it is resolved).
+	SuddenDeathRound   int      `json:"sudden_death_round,omitempty"`
+	SuddenDeathPlayers []string `json:"sudden_death_players,omitempty"`
+
+	// Version increments on every save. A save only succeeds against the
+	// version the state was loaded at.
+	Version int `json:"version"`
 }
 
+// GameSettings are per-game options resolved from the quiz when the game starts.
+type GameSettings struct {
+	ScoringStrategy string `json:"scoring_strategy"`
+	StreakSchedule  []int  `json:"streak_schedule,omitempty"` // empty = DefaultStreakSchedule
+
+	AutoAdvance      bool `json:"auto_advance,omitempty"`
+	AutoAdvanceDelay int  `json:"auto_advance_delay,omitempty"` // seconds on the leaderboard
+
+	Mode     models.SessionMode `json:"mode,omitempty"`      // empty = live
+	ClosesAt *time.Time         `json:"closes_at,omitempty"` // self-paced window end
+
+	TeamScoring models.TeamScoring `json:"team_scoring,omitempty"` // empty = no teams
+
+	ShuffleQuestions bool                 `json:"shuffle_questions,omitempty"`
+	ShuffleOptions   models.OptionShuffle `json:"shuffle_options,omitempty"`
+	QuestionCount    int                  `json:"question_count,omitempty"` // 0 = all
+
+	Elimination      models.EliminationMode `json:"elimination,omitempty"`
+	EliminationCount int                    `json:"elimination_count,omitempty"` // bottom mode: players out per question
+	Lives            int                    `json:"lives,omitempty"`             // lives mode: lives each player starts with
+
+	NegativeScores bool `json:"negative_scores,omitempty"` // lost wagers and penalties may take scores below zero
+
+	TieBreaker models.TieBreaker `json:"tie_breaker,omitempty"` // empty = time
+}
+
+// keyTTL is how long a game's Redis keys should live: a day for live games,
+// or until a day after the window closes for self-paced ones.
+func (g GameSettings) keyTTL() time.Duration {
+	if g.Mode == models.SessionModeSelfPaced && g.ClosesAt != nil {
+		return max(time.Until(*g.ClosesAt), 0) + 24*time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// Bounds for GameSettings.AutoAdvanceDelay, in seconds.
+const (
+	DefaultAutoAdvanceDelay = 8
+	MinAutoAdvanceDelay     = 3
+	MaxAutoAdvanceDelay     = 120
+)
+
+const (
+	// StartCountdownSeconds is how long clients get to reach the game page
+	// before the first question.
+	StartCountdownSeconds = 3
+	// RevealSeconds is how long an answer reveal shows before the leaderboard.
+	RevealSeconds = 3
+)
+
 // storedQuestion is the full question (including correct answers) cached in Redis.
 type storedQuestion struct {
-	ID        string         `json:"id"`
-	Text      string         `json:"text"`
-	TimeLimit int            `json:"time_limit"`
-	Order     int            `json:"order"`
-	Options   []storedOption `json:"options"`
+	ID               string              `json:"id"`
+	Type             models.QuestionType `json:"type"`
+	Text             string              `json:"text"`
+	TimeLimit        int                 `json:"time_limit"`
+	Order            int                 `json:"order"`
+	PartialCredit    bool                `json:"partial_credit"`
+	ScoringStrategy  string              `json:"scoring_strategy"`            // resolved at StartGame: question override, else quiz's
+	PointsMultiplier *int                `json:"points_multiplier,omitempty"` // question's 0x, 1x or 2x combined with its round's; nil means 1x
+	Options          []storedOption      `json:"options"`
+
+	AcceptedAnswers  []string `json:"accepted_answers,omitempty"`
+	CaseSensitive    bool     `json:"case_sensitive,omitempty"`
+	IgnoreDiacritics bool     `json:"ignore_diacritics,omitempty"`
+	MaxDistance      int      `json:"max_distance,omitempty"`
+
+	NumericTarget    *float64 `json:"numeric_target,omitempty"`
+	NumericTolerance float64  `json:"numeric_tolerance,omitempty"`
+
+	Wager bool `json:"wager,omitempty"` // live sessions open it with a wagering phase
 }
 
 type storedOption struct {
 	ID        string `json:"id"`
 	Text      string `json:"text"`
 	IsCorrect bool   `json:"is_correct"`
+	Ordinal   int    `json:"ordinal"` // correct position for ordering questions
 }
 
 // playerAnswer tracks a single player's answer in Redis.
 type playerAnswer struct {
-	OptionID   string    `json:"option_id"`
+	OptionID   string    `json:"option_id,omitempty"`
+	OptionIDs  []string  `json:"option_ids,omitempty"`
+	Text       string    `json:"text,omitempty"`
+	Number     *float64  `json:"number,omitempty"`
+	Judged     *bool     `json:"judged,omitempty"` // buzzer: the host's ruling
 	AnsweredAt time.Time `json:"answered_at"`
 }
 
 // revealScoreEntry is the per-player score included in answer_reveal.
 type revealScoreEntry struct {
-	IsCorrect  bool `json:"is_correct"`
-	Points     int  `json:"points"`
-	TotalScore int  `json:"total_score"`
+	IsCorrect   bool     `json:"is_correct"`
+	Points      int      `json:"points"`
+	TotalScore  int      `json:"total_score"`
+	OptionID    string   `json:"option_id,omitempty"` // single_choice, poll: the option picked
+	Answer      string   `json:"answer,omitempty"`    // typed_answer: what the player entered
+	Guess       *float64 `json:"guess,omitempty"`     // numeric: the player's guess
+	Streak      int      `json:"streak"`              // consecutive correct answers, including this one
+	StreakBonus int      `json:"streak_bonus"`        // bonus included in Points
+	Wager       *int     `json:"wager,omitempty"`     // wager questions: the stake, won or lost as Points
+}
+
+// guessBucket counts identical guesses for a numeric question's reveal.
+type guessBucket struct {
+	Value float64 `json:"value"`
+	Count int     `json:"count"`
+}
+
+// typedAnswerTally groups equivalent typed answers for the reveal screen.
+type typedAnswerTally struct {
+	Text      string `json:"text"`
+	Count     int    `json:"count"`
+	IsCorrect bool   `json:"is_correct"`
 }
 
 // Engine orchestrates the game loop: question broadcast, answer collection, reveal, leaderboard.
 type Engine struct {
-	hub    *hub.Hub
-	db     *pgxpool.Pool
-	redis  *redis.Client
-	mu     sync.Mutex
-	timers map[string]chan struct{} // sessionCode -> cancel channel
+	hub   *hub.Hub
+	db    *pgxpool.Pool
+	redis *redis.Client
 }
 
 // New creates a new Engine.
 func NewEngine(h *hub.Hub, db *pgxpool.Pool, redisClient *redis.Client) *Engine {
 	return &Engine{
-		hub:    h,
-		db:     db,
-		redis:  redisClient,
-		timers: make(map[string]chan struct{}),
+		hub:   h,
+		db:    db,
+		redis: redisClient,
 	}
 }
 
@@ -90,6 +233,12 @@ func redisKeyState(code string) string { return fmt.Sprintf("game:%s:state", cod
 // redisKeyQuestions returns the Redis key for cached questions.
 func redisKeyQuestions(code string) string { return fmt.Sprintf("game:%s:questions", code) }
 
+// redisKeyStreaks returns the Redis key for per-player correct-answer streaks.
+func redisKeyStreaks(code string) string { return fmt.Sprintf("game:%s:streaks", code) }
+
+// redisKeyProgress returns the Redis key for self-paced players' progress.
+func redisKeyProgress(code string) string { return fmt.Sprintf("game:%s:progress", code) }
+
 // redisKeyAnswers returns the Redis key for answers for a question index.
 func redisKeyAnswers(code string, idx int) string {
 	return fmt.Sprintf("game:%s:q%d:answers", code, idx)
@@ -99,12 +248,52 @@ func redisKeyAnswers(code string, idx int) string {
 // countdown before broadcasting the first question. This gives clients time to
 // navigate from the lobby to the game page.
 func (e *Engine) StartGame(ctx context.Context, sessionCode, sessionID, quizID string) error {
-	questions, err := e.loadQuestions(ctx, quizID)
+	// Mark the game as starting until it is saved or fails, so that should
+	// this node stop in between, recovery knows to start it again.
+	if err := e.redis.Set(ctx, redisKeyStarting(sessionCode), 1, startingTTL).Err(); err != nil {
+		return err
+	}
+	defer e.redis.Del(ctx, redisKeyStarting(sessionCode))
+
+	settings, err := e.loadSettings(ctx, sessionID, quizID)
 	if err != nil {
-		return fmt.Errorf("load questions: %w", err)
+		return fmt.Errorf("load settings: %w", err)
 	}
-	if len(questions) == 0 {
-		return fmt.Errorf("quiz has no questions")
+	rounds, err := e.loadRounds(ctx, sessionID)
+	if err != nil {
+		return fmt.Errorf("load rounds: %w", err)
+	}
+
+	var questions []storedQuestion
+	var roundInfos []RoundInfo
+	if len(rounds) > 0 {
+		if questions, roundInfos, err = e.roundQuestions(ctx, rounds, settings); err != nil {
+			return err
+		}
+	} else {
+		if questions, err = e.quizQuestions(ctx, quizID); err != nil {
+			return err
+		}
+		if len(questions) == 0 {
+			return fmt.Errorf("quiz has no questions")
+		}
+		questions = withoutBuzzerQuestions(questions, settings)
+		if len(questions) == 0 {
+			return fmt.Errorf("quiz has no questions playable in a %s session", settings.Mode)
+		}
+		questions = arrangeQuestions(questions, settings)
+		resolveScoringStrategies(questions, settings.ScoringStrategy)
+	}
+	shuffleOrderingOptions(questions)
+	if err := e.recordSessionQuestions(ctx, sessionID, questions, roundInfos); err != nil {
+		return fmt.Errorf("record session questions: %w", err)
+	}
+	if settings.Elimination == models.EliminationLives {
+		if _, err := e.db.Exec(ctx,
+			`UPDATE game_players SET lives = $1 WHERE session_id = $2`, settings.Lives, sessionID,
+		); err != nil {
+			return fmt.Errorf("set lives: %w", err)
+		}
 	}
 
 	// Cache questions in Redis (TTL 24h).
@@ -112,9 +301,14 @@ func (e *Engine) StartGame(ctx context.Context, sessionCode, sessionID, quizID s
 	if err != nil {
 		return err
 	}
-	if err := e.redis.Set(ctx, redisKeyQuestions(sessionCode), data, 24*time.Hour).Err(); err != nil {
+	if err := e.redis.Set(ctx, redisKeyQuestions(sessionCode), data, settings.keyTTL()).Err(); err != nil {
 		return err
 	}
+	if settings.TieBreaker == models.TieBreakerSuddenDeath && settings.Mode != models.SessionModeSelfPaced {
+		if err := e.cacheReserveQuestions(ctx, sessionCode, quizID, settings); err != nil {
+			return fmt.Errorf("cache reserve questions: %w", err)
+		}
+	}
 
 	state := &GameState{
 		SessionCode:    sessionCode,
@@ -122,21 +316,33 @@ func (e *Engine) StartGame(ctx context.Context, sessionCode, sessionID, quizID s
 		CurrentIndex:   0,
 		TotalQuestions: len(questions),
 		Phase:          PhaseStarting,
+		Settings:       settings,
+		Rounds:         roundInfos,
+	}
+	if settings.Mode == models.SessionModeSelfPaced {
+		// No shared countdown: each player opens questions as they go.
+		state.Phase = PhaseSelfPaced
+		if err := e.saveState(ctx, sessionCode, state); err != nil {
+			return err
+		}
+		return e.scheduleClose(ctx, sessionCode, settings.ClosesAt)
 	}
 	if err := e.saveState(ctx, sessionCode, state); err != nil {
 		return err
 	}
 
 	// Broadcast first question after a short delay so clients can navigate.
-	go func() {
-		time.Sleep(3 * time.Second)
-		bgCtx := context.Background()
-		if err := e.broadcastQuestion(bgCtx, sessionCode, 0); err != nil {
-			log.Printf("engine: broadcastQuestion error: %v", err)
-		}
-	}()
+	return e.schedule(ctx, timer{Code: sessionCode, Kind: timerStart}, time.Now().Add(StartCountdownSeconds*time.Second))
+}
 
-	return nil
+// openFirstQuestion ends the start countdown, unless the game has already
+// moved on.
+func (e *Engine) openFirstQuestion(ctx context.Context, sessionCode string) error {
+	st, err := e.loadState(ctx, sessionCode)
+	if err != nil || st.Phase != PhaseStarting {
+		return nil
+	}
+	return e.broadcastQuestion(ctx, sessionCode, 0)
 }
 
 // GetCurrentState retrieves the current GameState from Redis.
@@ -144,67 +350,97 @@ func (e *Engine) GetCurrentState(ctx context.Context, sessionCode string) (*Game
 	return e.loadState(ctx, sessionCode)
 }
 
-// GetCurrentQuestion returns the question at the current index for sending to a late-joining client.
-func (e *Engine) GetCurrentQuestion(ctx context.Context, sessionCode string) (*hub.Message, error) {
+// GetCurrentQuestion returns the question at the current index for sending to a late-joining player.
+func (e *Engine) GetCurrentQuestion(ctx context.Context, sessionCode, playerID string) (*hub.Message, error) {
 	state, err := e.loadState(ctx, sessionCode)
 	if err != nil {
 		return nil, err
 	}
-	if state.Phase != PhaseQuestion {
+	if !state.Phase.questionOnScreen() {
 		return nil, nil
 	}
 	questions, err := e.loadCachedQuestions(ctx, sessionCode)
 	if err != nil {
 		return nil, err
 	}
-	q := questions[state.CurrentIndex]
+	q := playerView(questions[state.CurrentIndex], state.Settings, state.SessionID, playerID)
 	msg := hub.Message{
 		Type:    hub.MsgQuestion,
-		Payload: buildQuestionPayload(q, state.CurrentIndex, state.TotalQuestions),
+		Payload: state.addRound(buildQuestionPayload(q, state.CurrentIndex, state.TotalQuestions), state.CurrentIndex),
 	}
 	return &msg, nil
 }
 
 // SubmitAnswer records a player's answer and triggers reveal if all players have answered.
-func (e *Engine) SubmitAnswer(ctx context.Context, sessionCode, playerID, questionIDStr, optionIDStr string) error {
+func (e *Engine) SubmitAnswer(ctx context.Context, sessionCode, playerID string, sub Submission) error {
+	return retryOnConflict(func() error {
+		return e.submitAnswer(ctx, sessionCode, playerID, sub)
+	})
+}
+
+func (e *Engine) submitAnswer(ctx context.Context, sessionCode, playerID string, sub Submission) error {
 	state, err := e.loadState(ctx, sessionCode)
 	if err != nil {
 		return fmt.Errorf("load state: %w", err)
 	}
+	if state.Phase == PhaseSelfPaced {
+		return e.submitSelfPaced(ctx, state, playerID, sub)
+	}
+	if state.Phase == PhaseBuzzerFloor {
+		return e.submitBuzzerAnswer(ctx, state, playerID, sub)
+	}
+	if state.Phase == PhaseSuddenDeath {
+		return e.submitSuddenDeathAnswer(ctx, state, playerID, sub)
+	}
 	if state.Phase != PhaseQuestion {
 		return fmt.Errorf("not in question phase (current: %s)", state.Phase)
 	}
+	if state.Paused {
+		return fmt.Errorf("question is paused")
+	}
+	if state.IsEliminated(playerID) {
+		return fmt.Errorf("player has been eliminated")
+	}
 
 	questions, err := e.loadCachedQuestions(ctx, sessionCode)
 	if err != nil {
 		return err
 	}
 	q := questions[state.CurrentIndex]
-	if q.ID != questionIDStr {
+	if q.ID != sub.QuestionID {
 		return fmt.Errorf("question_id mismatch")
 	}
+	if q.Type == models.QuestionTypeBuzzer {
+		return fmt.Errorf("buzz before answering a buzzer question")
+	}
+	ans, err := validateSubmission(q, sub)
+	if err != nil {
+		return fmt.Errorf("invalid answer: %w", err)
+	}
 
-	// Store answer in Redis (idempotent — first answer wins).
+	// Store answer in Redis (idempotent — first answer wins). It only counts
+	// if the question is still open, so it cannot land after the reveal has
+	// read the answers.
 	answerKey := redisKeyAnswers(sessionCode, state.CurrentIndex)
-	existing, err := e.redis.HGet(ctx, answerKey, playerID).Result()
-	if err == nil && existing != "" {
+	ans.AnsweredAt = time.Now()
+	ansData, _ := json.Marshal(ans)
+	placed, err := e.setOnce(ctx, state, answerKey, playerID, string(ansData))
+	if err != nil {
+		return err
+	}
+	if !placed {
 		return nil // already answered
 	}
 
-	ans := playerAnswer{
-		OptionID:   optionIDStr,
-		AnsweredAt: time.Now(),
+	if q.Type == models.QuestionTypePoll {
+		e.broadcastPollTally(ctx, sessionCode, q, answerKey)
 	}
-	ansData, _ := json.Marshal(ans)
-	e.redis.HSet(ctx, answerKey, playerID, string(ansData))
-	e.redis.Expire(ctx, answerKey, 24*time.Hour)
 
-	// Check if all connected players have answered.
-	playerCount := e.hub.RoomPlayerCount(sessionCode)
+	// Check if all connected players still in the game have answered.
+	playerCount := e.connectedSurvivors(sessionCode, state)
 	answeredCount, _ := e.redis.HLen(ctx, answerKey).Result()
 	if playerCount > 0 && int(answeredCount) >= playerCount {
-		// Cancel the timer and reveal immediately.
-		e.cancelTimer(sessionCode)
+		// Reveal immediately; the reveal cancels the timer.
 		go func() {
 			bgCtx := context.Background()
 			if err := e.triggerReveal(bgCtx, sessionCode); err != nil {
@@ -216,20 +452,52 @@ func (e *Engine) SubmitAnswer(ctx context.Context, sessionCode, playerID, questi
 	return nil
 }
 
-// NextQuestion advances the game to the next question or to game_over.
-// Called by the host from the leaderboard screen.
+// broadcastPollTally sends the host the live vote counts for a poll question.
+func (e *Engine) broadcastPollTally(ctx context.Context, sessionCode string, q storedQuestion, answerKey string) {
+	rawAnswers, err := e.redis.HGetAll(ctx, answerKey).Result()
+	if err != nil {
+		return
+	}
+	picks := make([]string, 0, len(rawAnswers))
+	for _, rawAns := range rawAnswers {
+		var ans playerAnswer
+		if err := json.Unmarshal([]byte(rawAns), &ans); err != nil {
+			continue
+		}
+		picks = append(picks, ans.OptionID)
+	}
+	e.hub.BroadcastToHost(sessionCode, hub.Message{
+		Type: hub.MsgPollTally,
+		Payload: map[string]any{
+			"question_id":       q.ID,
+			"vote_distribution": voteDistribution(q, picks),
+			"total_votes":       len(picks),
+		},
+	})
+}
+
+// NextQuestion advances the game to the next question or to game_over. At
+// the end of a round it opens the intermission instead, and from the
+// intermission it starts the next round. Called by the host from the
+// leaderboard screen.
 func (e *Engine) NextQuestion(ctx context.Context, sessionCode string) error {
 	state, err := e.loadState(ctx, sessionCode)
 	if err != nil {
 		return err
 	}
+	if state.Phase == PhaseIntermission {
+		return e.StartNextRound(ctx, sessionCode)
+	}
 	if state.Phase != PhaseLeaderboard {
 		return fmt.Errorf("can only advance from leaderboard phase (current: %s)", state.Phase)
 	}
 
 	next := state.CurrentIndex + 1
-	if next >= state.TotalQuestions {
-		return e.triggerGameOver(ctx, sessionCode)
+	if next >= state.TotalQuestions || e.lastPlayerStanding(ctx, state) {
+		return e.finishGame(ctx, sessionCode, state)
+	}
+	if state.startsRound(next) {
+		return e.openIntermission(ctx, sessionCode, state)
 	}
 	return e.broadcastQuestion(ctx, sessionCode, next)
 }
@@ -246,87 +514,134 @@ func (e *Engine) broadcastQuestion(ctx context.Context, sessionCode string, idx
 	if err != nil {
 		return err
 	}
+	if q.Wager && !(state.Phase == PhaseWager && state.CurrentIndex == idx) {
+		// Stakes first; closeWager comes back here to open the question.
+		return e.openWager(ctx, state, q, idx)
+	}
+	timeLimit := time.Duration(q.TimeLimit) * time.Second
 	state.CurrentIndex = idx
 	state.Phase = PhaseQuestion
 	state.QuestionStarted = time.Now()
+	state.QuestionDeadline = state.QuestionStarted.Add(timeLimit)
+	state.Paused = false
+	state.PausedAt = time.Time{}
+	state.PausedFor = 0
+	state.Remaining = 0
+	state.ExtraSeconds = 0
+	state.WagerDeadline = time.Time{}
+	state.resetBuzzer()
+	state.QuestionRun++
 	if err := e.saveState(ctx, sessionCode, state); err != nil {
 		return err
 	}
 
 	// Players receive the question without is_correct; host receives it with is_correct.
-	e.hub.BroadcastToPlayers(sessionCode, hub.Message{
-		Type:    hub.MsgQuestion,
-		Payload: buildQuestionPayload(q, idx, state.TotalQuestions),
-	})
+	if state.Settings.ShuffleOptions == models.OptionShufflePlayer {
+		for _, playerID := range e.hub.RoomPlayerIDs(sessionCode) {
+			e.hub.BroadcastToPlayer(sessionCode, playerID, hub.Message{
+				Type:    hub.MsgQuestion,
+				Payload: state.addRound(buildQuestionPayload(playerView(q, state.Settings, state.SessionID, playerID), idx, state.TotalQuestions), idx),
+			})
+		}
+	} else {
+		e.hub.BroadcastToPlayers(sessionCode, hub.Message{
+			Type:    hub.MsgQuestion,
+			Payload: state.addRound(buildQuestionPayload(q, idx, state.TotalQuestions), idx),
+		})
+	}
 	e.hub.BroadcastToHost(sessionCode, hub.Message{
 		Type:    hub.MsgQuestion,
-		Payload: BuildHostQuestionPayload(q, idx, state.TotalQuestions),
+		Payload: state.addRound(BuildHostQuestionPayload(q, idx, state.TotalQuestions), idx),
 	})
 
-	// Start question timer.
-	timeLimit := time.Duration(q.TimeLimit) * time.Second
-	cancel := make(chan struct{})
-	e.mu.Lock()
-	// Cancel any existing timer.
-	if old, ok := e.timers[sessionCode]; ok {
-		close(old)
-	}
-	e.timers[sessionCode] = cancel
-	e.mu.Unlock()
-
-	go func(code string, questionIdx int, cancelCh chan struct{}) {
-		select {
-		case <-time.After(timeLimit):
-			// Verify state is still this question before triggering.
-			bgCtx := context.Background()
-			st, err := e.loadState(bgCtx, code)
-			if err != nil || st.CurrentIndex != questionIdx || st.Phase != PhaseQuestion {
-				return
-			}
-			if err := e.triggerReveal(bgCtx, code); err != nil {
-				log.Printf("engine: timer reveal error: %v", err)
-			}
-		case <-cancelCh:
-			// Cancelled by SubmitAnswer (all answered) or NextQuestion.
-		}
-	}(sessionCode, idx, cancel)
-
+	e.startTimer(ctx, sessionCode, idx, timeLimit)
 	return nil
 }
 
 // triggerReveal broadcasts the correct answer, computes scores, persists to DB.
+// Of concurrent calls for the same question, such as the timer and the last
+// player answering, only one reveals.
 func (e *Engine) triggerReveal(ctx context.Context, sessionCode string) error {
+	return retryOnConflict(func() error {
+		return e.reveal(ctx, sessionCode)
+	})
+}
+
+func (e *Engine) reveal(ctx context.Context, sessionCode string) error {
 	state, err := e.loadState(ctx, sessionCode)
 	if err != nil {
 		return err
 	}
-	if state.Phase != PhaseQuestion {
+	if !state.Phase.questionOnScreen() {
 		return nil // already revealed
 	}
 
+	state.StreaksBefore = e.loadStreaks(ctx, sessionCode)
+	state.LivesBefore = maps.Clone(state.Lives)
 	state.Phase = PhaseReveal
+	state.Scored = false
 	if err := e.saveState(ctx, sessionCode, state); err != nil {
 		return err
 	}
+	e.cancelTimer(ctx, sessionCode)
 
-	questions, err := e.loadCachedQuestions(ctx, sessionCode)
+	if err := e.scoreReveal(ctx, sessionCode, state); err != nil {
+		// Try again shortly; answers already recorded are not scored twice.
+		t := timer{Code: sessionCode, Kind: timerReveal, Run: state.QuestionRun}
+		if err := e.schedule(ctx, t, time.Now().Add(RevealSeconds*time.Second)); err != nil {
+			log.Printf("engine: schedule reveal retry: %v", err)
+		}
+		return err
+	}
+	return nil
+}
+
+// finishReveal scores the reveal for question run if it never finished, as
+// when the node scoring it stopped part way.
+func (e *Engine) finishReveal(ctx context.Context, sessionCode string, run int) error {
+	state, err := e.loadState(ctx, sessionCode)
 	if err != nil {
 		return err
 	}
-	q := questions[state.CurrentIndex]
+	if state.Phase != PhaseReveal || state.QuestionRun != run || state.Scored {
+		return nil
+	}
+	return e.scoreReveal(ctx, sessionCode, state)
+}
 
-	// Find correct option.
-	var correctOptionID string
-	for _, opt := range q.Options {
-		if opt.IsCorrect {
-			correctOptionID = opt.ID
-			break
-		}
+// scoreReveal scores the answers to the question on reveal, broadcasts the
+// results and marks the reveal scored. Scoring works from the snapshots taken
+// when the reveal began and answers already recorded are left as they are, so
+// it can be run again after failing part way.
+func (e *Engine) scoreReveal(ctx context.Context, sessionCode string, state *GameState) error {
+	streaks := maps.Clone(state.StreaksBefore)
+	if streaks == nil {
+		streaks = make(map[string]int)
+	}
+
+	questions, err := e.loadCachedQuestions(ctx, sessionCode)
+	if err != nil {
+		return err
 	}
+	q := questions[state.CurrentIndex]
+	// Score against the time actually given, including host extensions.
+	q.TimeLimit += state.ExtraSeconds
 
 	// Load answers from Redis.
 	answerKey := redisKeyAnswers(sessionCode, state.CurrentIndex)
 	rawAnswers, _ := e.redis.HGetAll(ctx, answerKey).Result()
+	// Polls are unscored, so they neither extend nor break a streak.
+	scored := q.Type != models.QuestionTypePoll
+
+	var wagers, scoresBefore map[string]int
+	if q.Wager {
+		wagers = e.loadWagers(ctx, sessionCode, state.CurrentIndex)
+	}
+	if q.Wager || q.ScoringStrategy == ScoringNegative {
+		if scoresBefore, err = e.playerScores(ctx, state.SessionID); err != nil {
+			return err
+		}
+	}
 
 	scores := make(map[string]revealScoreEntry)
 	for playerID, rawAns := range rawAnswers {
@@ -334,105 +649,196 @@ func (e *Engine) triggerReveal(ctx context.Context, sessionCode string) error {
 		if err := json.Unmarshal([]byte(rawAns), &ans); err != nil {
 			continue
 		}
-		isCorrect := ans.OptionID == correctOptionID
-		points := 0
-		if isCorrect {
-			elapsed := ans.AnsweredAt.Sub(state.QuestionStarted).Seconds()
-			points = CalculatePoints(elapsed, q.TimeLimit)
+		elapsed := state.answerElapsed(ans.AnsweredAt)
+		isCorrect, points := evaluateAnswer(q, ans, elapsed)
+		points = PenaltyPoints(points, scoresBefore[playerID], state.Settings.NegativeScores)
+
+		streak, bonus := streaks[playerID], 0
+		if scored {
+			streak = 0
+			if isCorrect {
+				streak = streaks[playerID] + 1
+				bonus = StreakBonusFor(streak, state.Settings.StreakSchedule)
+				points += bonus
+			}
 		}
 
-		// Persist to DB.
-		playerUUID, err := uuid.Parse(playerID)
-		if err != nil {
-			continue
-		}
-		optionUUID, err := uuid.Parse(ans.OptionID)
-		if err != nil {
-			continue
+		entry := revealScoreEntry{
+			IsCorrect:   isCorrect,
+			Points:      points,
+			OptionID:    ans.OptionID,
+			Answer:      ans.Text,
+			Guess:       ans.Number,
+			Streak:      streak,
+			StreakBonus: bonus,
 		}
-		questionUUID, err := uuid.Parse(q.ID)
-		if err != nil {
-			continue
+		if q.Wager {
+			// The stake replaces speed points and the streak bonus.
+			wager := wagers[playerID]
+			entry.Wager = &wager
+			entry.Points = WagerPoints(isCorrect, wager, scoresBefore[playerID], state.Settings.NegativeScores)
+			entry.StreakBonus = 0
 		}
-		sessionUUID, err := uuid.Parse(state.SessionID)
-		if err != nil {
+		if err := e.recordAnswer(ctx, state, playerID, q, ans, elapsed, &entry); err != nil {
+			log.Printf("engine: record answer error: %v", err)
 			continue
 		}
+		streaks[playerID] = streak
+		scores[playerID] = entry
+	}
+	var forfeits map[string]revealScoreEntry
+	if q.Wager {
+		forfeits = e.forfeitWagers(ctx, state, q, wagers, scoresBefore, scores)
+	}
 
-		_, dbErr := e.db.Exec(ctx,
-			`INSERT INTO game_answers (id, session_id, player_id, question_id, option_id, answered_at, is_correct, points)
-			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-			 ON CONFLICT (session_id, player_id, question_id) DO NOTHING`,
-			uuid.New(), sessionUUID, playerUUID, questionUUID, optionUUID,
-			ans.AnsweredAt, isCorrect, points,
-		)
-		if dbErr != nil {
-			log.Printf("engine: insert answer error: %v", dbErr)
+	if scored {
+		// Players who did not answer lose their streak.
+		for playerID := range streaks {
+			if _, answered := scores[playerID]; !answered {
+				streaks[playerID] = 0
+			}
 		}
-
-		if points > 0 {
-			_, _ = e.db.Exec(ctx,
-				`UPDATE game_players SET score = score + $1 WHERE id = $2`,
-				points, playerUUID,
-			)
+		e.saveStreaks(ctx, sessionCode, streaks, state.Settings.keyTTL())
+		answered := make([]string, 0, len(scores))
+		for playerID := range scores {
+			answered = append(answered, playerID)
 		}
+		_, _ = e.db.Exec(ctx,
+			`UPDATE game_players SET streak = 0 WHERE session_id = $1 AND NOT (id::text = ANY($2::text[]))`,
+			state.SessionID, answered,
+		)
+	}
 
-		// Get total score for this player.
-		var totalScore int
-		_ = e.db.QueryRow(ctx,
-			`SELECT score FROM game_players WHERE id = $1`, playerUUID,
-		).Scan(&totalScore)
-
-		scores[playerID] = revealScoreEntry{
-			IsCorrect:  isCorrect,
-			Points:     points,
-			TotalScore: totalScore,
+	payload := buildRevealPayload(q, scores)
+	if q.Wager {
+		payload["forfeits"] = forfeits
+	}
+	if state.Settings.eliminationsEnabled() {
+		result, err := e.applyEliminations(ctx, state, q, scores)
+		if err != nil {
+			return fmt.Errorf("apply eliminations: %w", err)
+		}
+		payload["eliminated"] = result.Eliminated
+		if state.Settings.Elimination == models.EliminationLives {
+			payload["lives"] = result.Lives
+			payload["lives_lost"] = result.LivesLost
 		}
 	}
-
+	state.Scored = true
+	if err := e.saveState(ctx, sessionCode, state); err != nil {
+		return err
+	}
 	e.hub.Broadcast(sessionCode, hub.Message{
-		Type: hub.MsgAnswerReveal,
-		Payload: map[string]any{
-			"correct_option_id": correctOptionID,
-			"scores":            scores,
-		},
+		Type:    hub.MsgAnswerReveal,
+		Payload: payload,
 	})
 
-	// Auto-advance to leaderboard after 3 seconds.
-	go func() {
-		time.Sleep(3 * time.Second)
-		bgCtx := context.Background()
-		if err := e.broadcastLeaderboard(bgCtx, sessionCode); err != nil {
-			log.Printf("engine: broadcastLeaderboard error: %v", err)
+	// Auto-advance to leaderboard after the reveal.
+	return e.schedule(ctx, timer{Code: sessionCode, Kind: timerLeaderboard, Run: state.QuestionRun}, time.Now().Add(RevealSeconds*time.Second))
+}
+
+// recordAnswer persists a graded answer to game_answers, applies its points
+// and streak to the player, and fills in entry.TotalScore. The option order the
+// player was shown and the elapsed seconds they took are stored alongside it.
+// Recording an answer already on file changes nothing, so a reveal that is run
+// again scores each player once.
+func (e *Engine) recordAnswer(ctx context.Context, state *GameState, playerID string, q storedQuestion, ans playerAnswer, elapsed float64, entry *revealScoreEntry) error {
+	playerUUID, err := uuid.Parse(playerID)
+	if err != nil {
+		return err
+	}
+	var optionUUID *uuid.UUID
+	if ans.OptionID != "" {
+		id, err := uuid.Parse(ans.OptionID)
+		if err != nil {
+			return err
 		}
-	}()
+		optionUUID = &id
+	}
+	questionUUID, err := uuid.Parse(q.ID)
+	if err != nil {
+		return err
+	}
+	sessionUUID, err := uuid.Parse(state.SessionID)
+	if err != nil {
+		return err
+	}
 
-	return nil
+	tx, err := e.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	tag, err := tx.Exec(ctx,
+		`INSERT INTO game_answers (id, session_id, player_id, question_id, option_id, option_ids, text_answer, numeric_answer,
+		                           answered_at, is_correct, points, streak, streak_bonus, option_order, wager, answer_ms)
+		 VALUES ($1, $2, $3, $4, $5, $6::text[]::uuid[], NULLIF($7, ''), $8, $9, $10, $11, $12, $13, $14::text[]::uuid[], $15, $16)
+		 ON CONFLICT (session_id, player_id, question_id) DO NOTHING`,
+		uuid.New(), sessionUUID, playerUUID, questionUUID, optionUUID, ans.OptionIDs, ans.Text, ans.Number,
+		ans.AnsweredAt, entry.IsCorrect, entry.Points, entry.Streak, entry.StreakBonus,
+		playerView(q, state.Settings, state.SessionID, playerID).optionIDs(), entry.Wager, max(int(elapsed*1000), 0),
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return tx.QueryRow(ctx, `SELECT score FROM game_players WHERE id = $1`, playerUUID).Scan(&entry.TotalScore)
+	}
+	if err := tx.QueryRow(ctx,
+		`UPDATE game_players SET score = score + $1, streak = $2, best_streak = GREATEST(best_streak, $2) WHERE id = $3
+		 RETURNING score`,
+		entry.Points, entry.Streak, playerUUID,
+	).Scan(&entry.TotalScore); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
-// broadcastLeaderboard sends the current leaderboard to all clients.
-func (e *Engine) broadcastLeaderboard(ctx context.Context, sessionCode string) error {
+// broadcastLeaderboard sends the current leaderboard to all clients. It does
+// nothing unless the reveal for question run is still on screen.
+func (e *Engine) broadcastLeaderboard(ctx context.Context, sessionCode string, run int) error {
 	state, err := e.loadState(ctx, sessionCode)
 	if err != nil {
 		return err
 	}
+	if state.Phase != PhaseReveal || state.QuestionRun != run {
+		return nil // host re-ran or moved on during the reveal
+	}
 	state.Phase = PhaseLeaderboard
 	if err := e.saveState(ctx, sessionCode, state); err != nil {
 		return err
 	}
 
-	entries, err := e.getLeaderboard(ctx, state.SessionID)
+	payload, err := e.leaderboardPayload(ctx, state)
 	if err != nil {
 		return err
 	}
-
+	if state.Settings.AutoAdvance {
+		delay := time.Duration(state.Settings.AutoAdvanceDelay) * time.Second
+		payload["auto_advance_ms"] = delay.Milliseconds()
+		if err := e.schedule(ctx, timer{Code: sessionCode, Kind: timerAdvance, Run: run}, time.Now().Add(delay)); err != nil {
+			return err
+		}
+	}
 	e.hub.Broadcast(sessionCode, hub.Message{
 		Type:    hub.MsgLeaderboard,
-		Payload: map[string]any{"entries": entries},
+		Payload: payload,
 	})
 	return nil
 }
 
+// autoAdvance moves a hostless game on from the leaderboard or an
+// intermission when its delay is up, unless the host (if any) has already
+// done so.
+func (e *Engine) autoAdvance(ctx context.Context, sessionCode string, run int) error {
+	st, err := e.loadState(ctx, sessionCode)
+	if err != nil || (st.Phase != PhaseLeaderboard && st.Phase != PhaseIntermission) || st.QuestionRun != run {
+		return nil
+	}
+	return e.NextQuestion(ctx, sessionCode)
+}
+
 // triggerGameOver broadcasts the final podium.
 func (e *Engine) triggerGameOver(ctx context.Context, sessionCode string) error {
 	state, err := e.loadState(ctx, sessionCode)
@@ -450,14 +856,14 @@ func (e *Engine) triggerGameOver(ctx context.Context, sessionCode string) error
 		sessionCode,
 	)
 
-	entries, err := e.getLeaderboard(ctx, state.SessionID)
+	payload, err := e.leaderboardPayload(ctx, state)
 	if err != nil {
 		return err
 	}
 
 	e.hub.Broadcast(sessionCode, hub.Message{
 		Type:    hub.MsgPodium,
-		Payload: map[string]any{"entries": entries},
+		Payload: payload,
 	})
 	return nil
 }
@@ -465,40 +871,85 @@ func (e *Engine) triggerGameOver(ctx context.Context, sessionCode string) error
 // EndGame forcefully ends the game (e.g. host ended session early).
 // Broadcasts game_over with reason="session_ended" and cleans up Redis.
 func (e *Engine) EndGame(ctx context.Context, sessionCode string) {
-	e.cancelTimer(sessionCode)
-
 	e.hub.Broadcast(sessionCode, hub.Message{
 		Type: hub.MsgGameOver,
 		Payload: map[string]any{
 			"reason": "session_ended",
 		},
 	})
+	e.clearGame(ctx, sessionCode)
+}
 
-	// Clean up Redis keys.
+// clearGame cancels a game's timers and removes its keys from Redis.
+func (e *Engine) clearGame(ctx context.Context, sessionCode string) {
+	e.unschedule(ctx, sessionCode, timerKinds...)
 	state, err := e.loadState(ctx, sessionCode)
 	if err == nil {
 		for i := 0; i < state.TotalQuestions; i++ {
 			e.redis.Del(ctx, redisKeyAnswers(sessionCode, i))
+			e.redis.Del(ctx, redisKeyWagers(sessionCode, i))
+		}
+		for n := 1; n <= state.SuddenDeathRound; n++ {
+			e.redis.Del(ctx, redisKeySuddenDeath(sessionCode, n))
 		}
 	}
 	e.redis.Del(ctx, redisKeyState(sessionCode))
 	e.redis.Del(ctx, redisKeyQuestions(sessionCode))
+	e.redis.Del(ctx, redisKeyReserve(sessionCode))
+	e.redis.Del(ctx, redisKeyStreaks(sessionCode))
+	e.redis.Del(ctx, redisKeyProgress(sessionCode))
 }
 
-// cancelTimer cancels the active question timer for a session.
-func (e *Engine) cancelTimer(sessionCode string) {
-	e.mu.Lock()
-	defer e.mu.Unlock()
-	if ch, ok := e.timers[sessionCode]; ok {
-		close(ch)
-		delete(e.timers, sessionCode)
+// getLeaderboard queries DB for the session leaderboard. Players level on
+// points are ordered and ranked according to tb.
+func (e *Engine) getLeaderboard(ctx context.Context, sessionID string, tb models.TieBreaker) ([]models.LeaderboardEntry, error) {
+	rows, err := e.db.Query(ctx,
+		`SELECT p.id, p.name, p.score, p.streak, p.finished_at IS NOT NULL, p.team_id, COALESCE(t.name, ''),
+		        p.eliminated_at IS NOT NULL, CASE WHEN s.elimination = 'lives' THEN COALESCE(p.lives, s.lives) END,
+		        p.tiebreak
+		 FROM game_players p
+		 JOIN game_sessions s ON s.id = p.session_id
+		 LEFT JOIN game_teams t ON t.id = p.team_id
+		 WHERE p.session_id = $1
+		 ORDER BY p.name, p.id`,
+		sessionID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ranked []leaderboardRow
+	for rows.Next() {
+		var r leaderboardRow
+		e := &r.entry
+		if err := rows.Scan(&e.PlayerID, &e.Name, &e.Score, &e.Streak, &e.Finished, &e.TeamID, &e.TeamName, &e.Eliminated, &e.Lives,
+			&r.tiebreak); err != nil {
+			return nil, err
+		}
+		ranked = append(ranked, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
+
+	answers, err := e.loadTimedAnswers(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	addAnswerTimes(ranked, answers)
+	sortLeaderboard(ranked)
+	return rankLeaderboard(ranked, tb), nil
 }
 
-// getLeaderboard queries DB for the session leaderboard.
-func (e *Engine) getLeaderboard(ctx context.Context, sessionID string) ([]models.LeaderboardEntry, error) {
+// loadTimedAnswers returns every answer recorded in a session with the time
+// limit of its question.
+func (e *Engine) loadTimedAnswers(ctx context.Context, sessionID string) ([]timedAnswer, error) {
 	rows, err := e.db.Query(ctx,
-		`SELECT id, name, score FROM game_players WHERE session_id = $1 ORDER BY score DESC`,
+		`SELECT a.player_id::text, a.question_id::text, a.answer_ms, a.answered_at, a.is_correct, q.time_limit
+		 FROM game_answers a
+		 JOIN questions q ON q.id = a.question_id
+		 WHERE a.session_id = $1`,
 		sessionID,
 	)
 	if err != nil {
@@ -506,28 +957,53 @@ func (e *Engine) getLeaderboard(ctx context.Context, sessionID string) ([]models
 	}
 	defer rows.Close()
 
-	var entries []models.LeaderboardEntry
-	rank := 1
+	var answers []timedAnswer
 	for rows.Next() {
-		var e models.LeaderboardEntry
-		if err := rows.Scan(&e.PlayerID, &e.Name, &e.Score); err != nil {
+		var a timedAnswer
+		var limit int
+		if err := rows.Scan(&a.playerID, &a.questionID, &a.answerMs, &a.answeredAt, &a.correct, &limit); err != nil {
 			return nil, err
 		}
-		e.Rank = rank
-		rank++
-		entries = append(entries, e)
+		a.limitMs = int64(limit) * 1000
+		answers = append(answers, a)
+	}
+	return answers, rows.Err()
+}
+
+// quizQuestions returns a quiz's own questions followed by those drawn from
+// its owner's question bank.
+func (e *Engine) quizQuestions(ctx context.Context, quizID string) ([]storedQuestion, error) {
+	questions, err := e.loadQuestions(ctx, quizID)
+	if err != nil {
+		return nil, fmt.Errorf("load questions: %w", err)
 	}
-	if entries == nil {
-		entries = []models.LeaderboardEntry{}
+	drawn, err := e.drawBankQuestions(ctx, quizID, questions)
+	if err != nil {
+		return nil, fmt.Errorf("draw bank questions: %w", err)
 	}
-	return entries, nil
+	return append(questions, drawn...), nil
 }
 
 // loadQuestions fetches questions with options from DB.
 func (e *Engine) loadQuestions(ctx context.Context, quizID string) ([]storedQuestion, error) {
+	return e.queryQuestions(ctx, `WHERE quiz_id = $1 AND NOT reserve ORDER BY "order" ASC`, quizID)
+}
+
+// loadReserveQuestions fetches a quiz's reserve questions, in the order
+// sudden death asks them.
+func (e *Engine) loadReserveQuestions(ctx context.Context, quizID string) ([]storedQuestion, error) {
+	return e.queryQuestions(ctx, `WHERE quiz_id = $1 AND reserve ORDER BY "order" ASC`, quizID)
+}
+
+// queryQuestions loads questions and their options; clause filters and
+// orders the questions table.
+func (e *Engine) queryQuestions(ctx context.Context, clause string, args ...any) ([]storedQuestion, error) {
 	rows, err := e.db.Query(ctx,
-		`SELECT id, text, time_limit, "order" FROM questions WHERE quiz_id = $1 ORDER BY "order" ASC`,
-		quizID,
+		`SELECT id, type, text, time_limit, "order", partial_credit, COALESCE(scoring_strategy, ''), points_multiplier,
+		        accepted_answers, case_sensitive, ignore_diacritics, max_distance,
+		        numeric_target, numeric_tolerance, wager
+		 FROM questions `+clause,
+		args...,
 	)
 	if err != nil {
 		return nil, err
@@ -537,15 +1013,21 @@ func (e *Engine) loadQuestions(ctx context.Context, quizID string) ([]storedQues
 	var questions []storedQuestion
 	for rows.Next() {
 		var q storedQuestion
-		if err := rows.Scan(&q.ID, &q.Text, &q.TimeLimit, &q.Order); err != nil {
+		if err := rows.Scan(&q.ID, &q.Type, &q.Text, &q.TimeLimit, &q.Order, &q.PartialCredit, &q.ScoringStrategy, &q.PointsMultiplier,
+			&q.AcceptedAnswers, &q.CaseSensitive, &q.IgnoreDiacritics, &q.MaxDistance,
+			&q.NumericTarget, &q.NumericTolerance, &q.Wager); err != nil {
 			return nil, err
 		}
 		questions = append(questions, q)
 	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
 	for i := range questions {
 		optRows, err := e.db.Query(ctx,
-			`SELECT id, text, is_correct FROM options WHERE question_id = $1 ORDER BY id`,
+			`SELECT id, text, is_correct, position FROM options WHERE question_id = $1 ORDER BY position, id`,
 			questions[i].ID,
 		)
 		if err != nil {
@@ -553,7 +1035,7 @@ func (e *Engine) loadQuestions(ctx context.Context, quizID string) ([]storedQues
 		}
 		for optRows.Next() {
 			var opt storedOption
-			if err := optRows.Scan(&opt.ID, &opt.Text, &opt.IsCorrect); err != nil {
+			if err := optRows.Scan(&opt.ID, &opt.Text, &opt.IsCorrect, &opt.Ordinal); err != nil {
 				optRows.Close()
 				return nil, err
 			}
@@ -564,6 +1046,51 @@ func (e *Engine) loadQuestions(ctx context.Context, quizID string) ([]storedQues
 	return questions, nil
 }
 
+// loadSettings reads the per-game options stored on the quiz.
+func (e *Engine) loadSettings(ctx context.Context, sessionID, quizID string) (GameSettings, error) {
+	var settings GameSettings
+	err := e.db.QueryRow(ctx,
+		`SELECT q.scoring_strategy, q.streak_schedule, s.auto_advance, s.auto_advance_delay, s.mode, s.closes_at,
+		        CASE WHEN s.team_assignment = 'none' THEN '' ELSE s.team_scoring END, s.elimination, s.elimination_count,
+		        COALESCE(s.shuffle_questions, q.shuffle_questions), COALESCE(s.shuffle_options, q.shuffle_options),
+		        COALESCE(s.question_count, q.question_count, 0), q.negative_scores, s.lives, q.tie_breaker
+		 FROM quizzes q JOIN game_sessions s ON s.quiz_id = q.id
+		 WHERE q.id = $1 AND s.id = $2`, quizID, sessionID,
+	).Scan(&settings.ScoringStrategy, &settings.StreakSchedule, &settings.AutoAdvance, &settings.AutoAdvanceDelay,
+		&settings.Mode, &settings.ClosesAt, &settings.TeamScoring, &settings.Elimination, &settings.EliminationCount,
+		&settings.ShuffleQuestions, &settings.ShuffleOptions, &settings.QuestionCount, &settings.NegativeScores,
+		&settings.Lives, &settings.TieBreaker)
+	return settings, err
+}
+
+// loadStreaks returns each player's current correct-answer streak.
+func (e *Engine) loadStreaks(ctx context.Context, sessionCode string) map[string]int {
+	streaks := make(map[string]int)
+	raw, err := e.redis.HGetAll(ctx, redisKeyStreaks(sessionCode)).Result()
+	if err != nil {
+		return streaks
+	}
+	for playerID, v := range raw {
+		if n, err := strconv.Atoi(v); err == nil {
+			streaks[playerID] = n
+		}
+	}
+	return streaks
+}
+
+func (e *Engine) saveStreaks(ctx context.Context, sessionCode string, streaks map[string]int, ttl time.Duration) {
+	if len(streaks) == 0 {
+		return
+	}
+	values := make(map[string]any, len(streaks))
+	for playerID, n := range streaks {
+		values[playerID] = n
+	}
+	key := redisKeyStreaks(sessionCode)
+	e.redis.HSet(ctx, key, values)
+	e.redis.Expire(ctx, key, ttl)
+}
+
 func (e *Engine) loadCachedQuestions(ctx context.Context, sessionCode string) ([]storedQuestion, error) {
 	data, err := e.redis.Get(ctx, redisKeyQuestions(sessionCode)).Bytes()
 	if err != nil {
@@ -576,12 +1103,27 @@ func (e *Engine) loadCachedQuestions(ctx context.Context, sessionCode string) ([
 	return questions, nil
 }
 
+// saveState stores state if the stored state is still the version it was
+// loaded at, and returns errStateChanged if not.
 func (e *Engine) saveState(ctx context.Context, sessionCode string, state *GameState) error {
+	loaded := state.Version
+	state.Version++
 	data, err := json.Marshal(state)
 	if err != nil {
+		state.Version = loaded
 		return err
 	}
-	return e.redis.Set(ctx, redisKeyState(sessionCode), data, 24*time.Hour).Err()
+	saved, err := saveStateScript.Run(ctx, e.redis,
+		[]string{redisKeyState(sessionCode)},
+		data, loaded, state.Settings.keyTTL().Milliseconds(),
+	).Int()
+	if err == nil && saved == 0 {
+		err = errStateChanged
+	}
+	if err != nil {
+		state.Version = loaded
+	}
+	return err
 }
 
 func (e *Engine) loadState(ctx context.Context, sessionCode string) (*GameState, error) {
@@ -607,15 +1149,18 @@ func buildQuestionPayload(q storedQuestion, idx, total int) map[string]any {
 		"question_index":  idx,
 		"total_questions": total,
 		"question": map[string]any{
-			"id":         q.ID,
-			"text":       q.Text,
-			"time_limit": q.TimeLimit,
-			"options":    opts,
+			"id":                q.ID,
+			"type":              q.Type,
+			"text":              q.Text,
+			"time_limit":        q.TimeLimit,
+			"points_multiplier": q.multiplier(),
+			"options":           opts,
 		},
 	}
 }
 
-// BuildHostQuestionPayload is the same as buildQuestionPayload but includes is_correct.
+// BuildHostQuestionPayload is the same as buildQuestionPayload but includes is_correct
+// and, for typed-answer, numeric and ordering questions, the expected answer.
 func BuildHostQuestionPayload(q storedQuestion, idx, total int) map[string]any {
 	opts := make([]map[string]any, 0, len(q.Options))
 	for _, o := range q.Options {
@@ -625,16 +1170,127 @@ func BuildHostQuestionPayload(q storedQuestion, idx, total int) map[string]any {
 			"is_correct": o.IsCorrect,
 		})
 	}
+	question := map[string]any{
+		"id":                q.ID,
+		"type":              q.Type,
+		"text":              q.Text,
+		"time_limit":        q.TimeLimit,
+		"points_multiplier": q.multiplier(),
+		"options":           opts,
+	}
+	switch q.Type {
+	case models.QuestionTypeTypedAnswer:
+		question["accepted_answers"] = q.AcceptedAnswers
+	case models.QuestionTypeNumeric:
+		question["numeric_target"] = q.NumericTarget
+		question["numeric_tolerance"] = q.NumericTolerance
+	case models.QuestionTypeOrdering:
+		question["correct_order"] = q.correctOrder()
+	}
 	return map[string]any{
 		"question_index":  idx,
 		"total_questions": total,
-		"question": map[string]any{
-			"id":         q.ID,
-			"text":       q.Text,
-			"time_limit": q.TimeLimit,
-			"options":    opts,
-		},
+		"question":        question,
+	}
+}
+
+// buildRevealPayload constructs the answer_reveal payload for q.
+// Typed-answer questions additionally carry the accepted answers and a tally
+// of what players entered, grouped by their normalised form. Numeric questions
+// carry the target, the distribution of guesses and the closest guess.
+// Ordering questions carry the correct sequence. Polls carry the vote
+// distribution instead of a correct answer.
+func buildRevealPayload(q storedQuestion, scores map[string]revealScoreEntry) map[string]any {
+	payload := map[string]any{
+		"question_type": q.Type,
+		"scores":        scores,
+	}
+	if q.Type == models.QuestionTypePoll {
+		picks := make([]string, 0, len(scores))
+		for _, entry := range scores {
+			picks = append(picks, entry.OptionID)
+		}
+		payload["vote_distribution"] = voteDistribution(q, picks)
+		return payload
+	}
+	payload["correct_option_id"] = q.correctOptionID()
+	payload["correct_option_ids"] = q.correctOptionIDs()
+	switch q.Type {
+	case models.QuestionTypeTypedAnswer:
+		payload["accepted_answers"] = q.AcceptedAnswers
+		payload["submitted_answers"] = tallyTypedAnswers(scores, q.matchRules())
+	case models.QuestionTypeOrdering:
+		payload["correct_order"] = q.correctOrder()
+	case models.QuestionTypeBuzzer:
+		payload["accepted_answers"] = q.AcceptedAnswers
+	case models.QuestionTypeNumeric:
+		payload["numeric_target"] = q.NumericTarget
+		payload["numeric_tolerance"] = q.NumericTolerance
+		payload["guess_distribution"] = guessDistribution(scores)
+		if q.NumericTarget != nil {
+			if closest, ok := closestGuess(scores, *q.NumericTarget); ok {
+				payload["closest_guess"] = closest
+			}
+		}
+	}
+	return payload
+}
+
+// guessDistribution counts numeric guesses by value, in ascending order.
+func guessDistribution(scores map[string]revealScoreEntry) []guessBucket {
+	counts := make(map[float64]int)
+	for _, entry := range scores {
+		if entry.Guess != nil {
+			counts[*entry.Guess]++
+		}
+	}
+	buckets := make([]guessBucket, 0, len(counts))
+	for v, n := range counts {
+		buckets = append(buckets, guessBucket{Value: v, Count: n})
 	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].Value < buckets[j].Value })
+	return buckets
+}
+
+// closestGuess returns the guess nearest to target. ok is false if nobody guessed.
+func closestGuess(scores map[string]revealScoreEntry, target float64) (closest float64, ok bool) {
+	best := math.Inf(1)
+	for _, entry := range scores {
+		if entry.Guess == nil {
+			continue
+		}
+		d := math.Abs(*entry.Guess - target)
+		if d < best || (d == best && *entry.Guess < closest) {
+			best, closest, ok = d, *entry.Guess, true
+		}
+	}
+	return closest, ok
+}
+
+// tallyTypedAnswers groups typed answers that normalise to the same text,
+// most common first. The first raw spelling seen is used for display.
+func tallyTypedAnswers(scores map[string]revealScoreEntry, rules TextMatchRules) []typedAnswerTally {
+	index := make(map[string]int)
+	tallies := []typedAnswerTally{}
+	for _, entry := range scores {
+		if entry.Answer == "" {
+			continue
+		}
+		key := NormalizeAnswer(entry.Answer, rules)
+		if i, ok := index[key]; ok {
+			tallies[i].Count++
+			continue
+		}
+		index[key] = len(tallies)
+		tallies = append(tallies, typedAnswerTally{Text: entry.Answer, Count: 1, IsCorrect: entry.IsCorrect})
+	}
+	sort.SliceStable(tallies, func(i, j int) bool {
+		if tallies[i].Count != tallies[j].Count {
+			return tallies[i].Count > tallies[j].Count
+		}
+		return tallies[i].Text < tallies[j].Text
+	})
+	return tallies
 }
 
 // GetHostQuestion returns the current question with is_correct included (for host display).
@@ -643,7 +1299,7 @@ func (e *Engine) GetHostQuestion(ctx context.Context, sessionCode string) (*hub.
 	if err != nil {
 		return nil, err
 	}
-	if state.Phase != PhaseQuestion {
+	if !state.Phase.questionOnScreen() {
 		return nil, nil
 	}
 	questions, err := e.loadCachedQuestions(ctx, sessionCode)
@@ -653,7 +1309,7 @@ func (e *Engine) GetHostQuestion(ctx context.Context, sessionCode string) (*hub.
 	q := questions[state.CurrentIndex]
 	msg := hub.Message{
 		Type:    hub.MsgQuestion,
-		Payload: BuildHostQuestionPayload(q, state.CurrentIndex, state.TotalQuestions),
+		Payload: state.addRound(BuildHostQuestionPayload(q, state.CurrentIndex, state.TotalQuestions), state.CurrentIndex),
 	}
 	return &msg, nil
 }
@@ -1,8 +1,12 @@
 package game
 
 import (
+	"math"
+	"slices"
 	"testing"
 	"time"
+
+	"github.com/HassanA01/Iftarootv2/backend/internal/models"
 )
 
 func TestBuildQuestionPayload(t *testing.T) {
@@ -177,7 +181,10 @@ func TestRevealPayloadFields(t *testing.T) {
 }
 
 func TestPhaseConstants(t *testing.T) {
-	phases := []GamePhase{PhaseStarting, PhaseQuestion, PhaseReveal, PhaseLeaderboard, PhaseGameOver}
+	phases := []GamePhase{
+		PhaseStarting, PhaseWager, PhaseQuestion, PhaseBuzzerFloor, PhaseBuzzerJudge,
+		PhaseReveal, PhaseLeaderboard, PhaseIntermission, PhaseSuddenDeath, PhaseGameOver, PhaseSelfPaced,
+	}
 	seen := make(map[GamePhase]bool)
 	for _, p := range phases {
 		if seen[p] {
@@ -189,3 +196,486 @@ func TestPhaseConstants(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateSubmissionMultiSelect(t *testing.T) {
+	q := storedQuestion{
+		ID:   "q1",
+		Type: models.QuestionTypeMultiSelect,
+		Options: []storedOption{
+			{ID: "o1", IsCorrect: true},
+			{ID: "o2", IsCorrect: true},
+			{ID: "o3"},
+		},
+	}
+
+	ans, err := validateSubmission(q, Submission{QuestionID: "q1", OptionIDs: []string{"o1", "o2", "o1"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ans.OptionIDs) != 2 {
+		t.Errorf("expected duplicates to be dropped, got %v", ans.OptionIDs)
+	}
+
+	if _, err := validateSubmission(q, Submission{QuestionID: "q1"}); err == nil {
+		t.Error("expected error for empty selection")
+	}
+	if _, err := validateSubmission(q, Submission{QuestionID: "q1", OptionIDs: []string{"nope"}}); err == nil {
+		t.Error("expected error for unknown option")
+	}
+}
+
+func TestValidateSubmissionSingleChoice(t *testing.T) {
+	q := storedQuestion{
+		ID:      "q1",
+		Options: []storedOption{{ID: "o1", IsCorrect: true}, {ID: "o2"}},
+	}
+	if _, err := validateSubmission(q, Submission{QuestionID: "q1", OptionID: "o2"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := validateSubmission(q, Submission{QuestionID: "q1"}); err == nil {
+		t.Error("expected error for missing option_id")
+	}
+}
+
+func TestEvaluateAnswerMultiSelect(t *testing.T) {
+	q := storedQuestion{
+		Type:      models.QuestionTypeMultiSelect,
+		TimeLimit: 20,
+		Options: []storedOption{
+			{ID: "o1", IsCorrect: true},
+			{ID: "o2", IsCorrect: true},
+			{ID: "o3"},
+		},
+	}
+
+	correct, points := evaluateAnswer(q, playerAnswer{OptionIDs: []string{"o2", "o1"}}, 0)
+	if !correct || points != BasePoints {
+		t.Errorf("exact match: got correct=%v points=%d", correct, points)
+	}
+
+	correct, points = evaluateAnswer(q, playerAnswer{OptionIDs: []string{"o1"}}, 0)
+	if correct || points != 0 {
+		t.Errorf("partial answer without partial credit: got correct=%v points=%d", correct, points)
+	}
+
+	q.PartialCredit = true
+	correct, points = evaluateAnswer(q, playerAnswer{OptionIDs: []string{"o1"}}, 0)
+	if correct || points != BasePoints/2 {
+		t.Errorf("partial answer with partial credit: got correct=%v points=%d", correct, points)
+	}
+}
+
+func TestTypedAnswerReveal(t *testing.T) {
+	q := storedQuestion{
+		Type:            models.QuestionTypeTypedAnswer,
+		TimeLimit:       20,
+		AcceptedAnswers: []string{"Makkah"},
+		MaxDistance:     1,
+	}
+
+	ans, err := validateSubmission(q, Submission{Text: "  makah "})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ans.Text != "makah" {
+		t.Errorf("expected trimmed text, got %q", ans.Text)
+	}
+	if correct, points := evaluateAnswer(q, ans, 0); !correct || points != BasePoints {
+		t.Errorf("expected fuzzy match to score, got correct=%v points=%d", correct, points)
+	}
+	if _, err := validateSubmission(q, Submission{Text: "  "}); err == nil {
+		t.Error("expected error for blank text")
+	}
+
+	payload := buildRevealPayload(q, map[string]revealScoreEntry{
+		"p1": {IsCorrect: true, Answer: "Makkah"},
+		"p2": {IsCorrect: true, Answer: "makkah "},
+		"p3": {IsCorrect: false, Answer: "Madinah"},
+	})
+	tallies, ok := payload["submitted_answers"].([]typedAnswerTally)
+	if !ok {
+		t.Fatal("submitted_answers missing or wrong type")
+	}
+	if len(tallies) != 2 || tallies[0].Count != 2 || !tallies[0].IsCorrect {
+		t.Errorf("unexpected tallies: %+v", tallies)
+	}
+	if _, ok := payload["accepted_answers"]; !ok {
+		t.Error("expected accepted_answers in typed_answer reveal")
+	}
+}
+
+func TestQuestionPayloadHidesAcceptedAnswers(t *testing.T) {
+	q := storedQuestion{ID: "q1", Type: models.QuestionTypeTypedAnswer, AcceptedAnswers: []string{"secret"}}
+	inner := buildQuestionPayload(q, 0, 1)["question"].(map[string]any)
+	if _, ok := inner["accepted_answers"]; ok {
+		t.Error("player question payload must not include accepted_answers")
+	}
+	hostInner := BuildHostQuestionPayload(q, 0, 1)["question"].(map[string]any)
+	if _, ok := hostInner["accepted_answers"]; !ok {
+		t.Error("host question payload should include accepted_answers")
+	}
+}
+
+func TestNumericReveal(t *testing.T) {
+	target := 622.0
+	q := storedQuestion{
+		Type:             models.QuestionTypeNumeric,
+		TimeLimit:        20,
+		NumericTarget:    &target,
+		NumericTolerance: 10,
+	}
+
+	if _, err := validateSubmission(q, Submission{}); err == nil {
+		t.Error("expected error for missing number")
+	}
+	nan := math.NaN()
+	if _, err := validateSubmission(q, Submission{Number: &nan}); err == nil {
+		t.Error("expected error for NaN")
+	}
+
+	guess := 627.0
+	ans, err := validateSubmission(q, Submission{Number: &guess})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	correct, points := evaluateAnswer(q, ans, 0)
+	if !correct || points != 750 {
+		t.Errorf("expected correct with 750 points, got correct=%v points=%d", correct, points)
+	}
+	far := 700.0
+	if correct, points := evaluateAnswer(q, playerAnswer{Number: &far}, 0); correct || points != 0 {
+		t.Errorf("expected far guess to score 0, got correct=%v points=%d", correct, points)
+	}
+
+	g1, g2, g3 := 610.0, 627.0, 627.0
+	payload := buildRevealPayload(q, map[string]revealScoreEntry{
+		"p1": {Guess: &g1},
+		"p2": {Guess: &g2},
+		"p3": {Guess: &g3},
+		"p4": {},
+	})
+	dist, ok := payload["guess_distribution"].([]guessBucket)
+	if !ok {
+		t.Fatal("guess_distribution missing or wrong type")
+	}
+	if len(dist) != 2 || dist[0].Value != 610 || dist[1].Count != 2 {
+		t.Errorf("unexpected distribution: %+v", dist)
+	}
+	if payload["closest_guess"] != 627.0 {
+		t.Errorf("expected closest_guess=627, got %v", payload["closest_guess"])
+	}
+}
+
+func orderingQuestion() storedQuestion {
+	return storedQuestion{
+		ID:        "q1",
+		Type:      models.QuestionTypeOrdering,
+		TimeLimit: 20,
+		Options: []storedOption{
+			{ID: "badr", Ordinal: 1},
+			{ID: "hijra", Ordinal: 0},
+			{ID: "fath", Ordinal: 2},
+		},
+	}
+}
+
+func TestOrderingSubmissionAndScoring(t *testing.T) {
+	q := orderingQuestion()
+
+	if _, err := validateSubmission(q, Submission{OptionIDs: []string{"hijra", "badr"}}); err == nil {
+		t.Error("expected error when not all options are ordered")
+	}
+	if _, err := validateSubmission(q, Submission{OptionIDs: []string{"hijra", "hijra", "fath"}}); err == nil {
+		t.Error("expected error for repeated option")
+	}
+
+	ans, err := validateSubmission(q, Submission{OptionIDs: []string{"hijra", "badr", "fath"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if correct, points := evaluateAnswer(q, ans, 0); !correct || points != BasePoints {
+		t.Errorf("exact order: got correct=%v points=%d", correct, points)
+	}
+
+	q.PartialCredit = true
+	swapped := playerAnswer{OptionIDs: []string{"hijra", "fath", "badr"}}
+	if correct, points := evaluateAnswer(q, swapped, 0); correct || points != 333 {
+		t.Errorf("one of three in place: got correct=%v points=%d", correct, points)
+	}
+}
+
+func TestOrderingPayloads(t *testing.T) {
+	q := orderingQuestion()
+
+	hostInner := BuildHostQuestionPayload(q, 0, 1)["question"].(map[string]any)
+	order, ok := hostInner["correct_order"].([]string)
+	if !ok || len(order) != 3 || order[0] != "hijra" || order[2] != "fath" {
+		t.Errorf("unexpected host correct_order: %v", hostInner["correct_order"])
+	}
+	inner := buildQuestionPayload(q, 0, 1)["question"].(map[string]any)
+	if _, ok := inner["correct_order"]; ok {
+		t.Error("player question payload must not include correct_order")
+	}
+	opts := inner["options"].([]map[string]string)
+	for _, o := range opts {
+		if _, ok := o["ordinal"]; ok {
+			t.Error("player options must not include ordinal")
+		}
+	}
+}
+
+func TestShuffleOrderingOptions(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		questions := []storedQuestion{orderingQuestion()}
+		questions[0].Options = []storedOption{
+			{ID: "hijra", Ordinal: 0},
+			{ID: "badr", Ordinal: 1},
+			{ID: "fath", Ordinal: 2},
+		}
+		shuffleOrderingOptions(questions)
+		got := questions[0].optionIDs()
+		if got[0] == "hijra" && got[1] == "badr" && got[2] == "fath" {
+			t.Fatal("shuffled options must not be in the correct order")
+		}
+		if len(questions[0].correctOrder()) != 3 || questions[0].correctOrder()[0] != "hijra" {
+			t.Fatal("shuffling must preserve the correct order")
+		}
+	}
+}
+
+func TestPollReveal(t *testing.T) {
+	q := storedQuestion{
+		Type:      models.QuestionTypePoll,
+		TimeLimit: 20,
+		Options: []storedOption{
+			{ID: "dates", Text: "Dates"},
+			{ID: "soup", Text: "Lentil soup"},
+			{ID: "samosa", Text: "Samosa"},
+		},
+	}
+
+	if correct, points := evaluateAnswer(q, playerAnswer{OptionID: "dates"}, 0); correct || points != 0 {
+		t.Errorf("polls must not score, got correct=%v points=%d", correct, points)
+	}
+
+	payload := buildRevealPayload(q, map[string]revealScoreEntry{
+		"p1": {OptionID: "dates"},
+		"p2": {OptionID: "dates"},
+		"p3": {OptionID: "soup"},
+	})
+	if _, ok := payload["correct_option_id"]; ok {
+		t.Error("poll reveal must not include correct_option_id")
+	}
+	dist, ok := payload["vote_distribution"].([]voteCount)
+	if !ok {
+		t.Fatal("vote_distribution missing or wrong type")
+	}
+	want := []int{2, 1, 0}
+	for i, vc := range dist {
+		if vc.Count != want[i] {
+			t.Errorf("option %s: got %d votes, want %d", vc.OptionID, vc.Count, want[i])
+		}
+	}
+}
+
+func TestPointsMultiplier(t *testing.T) {
+	double, zero := 2, 0
+	q := storedQuestion{
+		ID:        "q1",
+		TimeLimit: 20,
+		Options:   []storedOption{{ID: "o1", IsCorrect: true}, {ID: "o2"}},
+	}
+	right := playerAnswer{OptionID: "o1"}
+
+	if _, points := evaluateAnswer(q, right, 0); points != BasePoints {
+		t.Errorf("unset multiplier should be 1x, got %d points", points)
+	}
+	q.PointsMultiplier = &double
+	if _, points := evaluateAnswer(q, right, 0); points != 2*BasePoints {
+		t.Errorf("2x: got %d points", points)
+	}
+	q.PointsMultiplier = &zero
+	if correct, points := evaluateAnswer(q, right, 0); !correct || points != 0 {
+		t.Errorf("0x: got correct=%v points=%d", correct, points)
+	}
+
+	q.PointsMultiplier = &double
+	inner := buildQuestionPayload(q, 0, 1)["question"].(map[string]any)
+	if inner["points_multiplier"] != 2 {
+		t.Errorf("expected points_multiplier=2 in player payload, got %v", inner["points_multiplier"])
+	}
+}
+
+func TestTimerPayloadAndElapsed(t *testing.T) {
+	start := time.Date(2026, 3, 1, 18, 0, 0, 0, time.UTC)
+	state := &GameState{
+		CurrentIndex:     2,
+		QuestionStarted:  start,
+		QuestionDeadline: start.Add(20 * time.Second),
+	}
+
+	p := buildTimerPayload(state, start.Add(5*time.Second))
+	if p["remaining_ms"] != int64(15000) || p["paused"] != false || p["deadline"] == nil {
+		t.Errorf("running payload = %v", p)
+	}
+
+	// Deadline already passed clamps to zero.
+	if p := buildTimerPayload(state, start.Add(30*time.Second)); p["remaining_ms"] != int64(0) {
+		t.Errorf("expired remaining_ms = %v, want 0", p["remaining_ms"])
+	}
+
+	state.Paused = true
+	state.Remaining = 7 * time.Second
+	state.QuestionDeadline = time.Time{}
+	p = buildTimerPayload(state, start.Add(time.Hour))
+	if p["remaining_ms"] != int64(7000) || p["paused"] != true {
+		t.Errorf("paused payload = %v", p)
+	}
+	if _, ok := p["deadline"]; ok {
+		t.Error("paused payload should not include a deadline")
+	}
+
+	// Time spent paused does not count against the player.
+	state.PausedFor = 10 * time.Second
+	if got := state.answerElapsed(start.Add(14 * time.Second)); got != 4 {
+		t.Errorf("answerElapsed = %v, want 4", got)
+	}
+}
+
+func TestSelfPacedClock(t *testing.T) {
+	start := time.Date(2026, 3, 1, 18, 0, 0, 0, time.UTC)
+	q := storedQuestion{ID: "q1", Type: models.QuestionTypeSingleChoice, Text: "?", TimeLimit: 20}
+
+	var unseen playerProgress
+	if unseen.expired(q, start.Add(time.Hour)) {
+		t.Error("a question never shown should not expire")
+	}
+
+	p := playerProgress{Index: 1, QuestionStarted: start}
+	if p.expired(q, start.Add(20*time.Second)) {
+		t.Error("answer on the deadline should be inside the grace period")
+	}
+	if !p.expired(q, start.Add(22*time.Second)) {
+		t.Error("answer well past the deadline should be expired")
+	}
+
+	payload := buildSelfPacedQuestionPayload(q, p, 5, start.Add(5*time.Second))
+	if payload["question_index"] != 1 || payload["remaining_ms"] != int64(15000) || payload["answered"] != false {
+		t.Errorf("payload = %v", payload)
+	}
+	if payload["deadline"] != start.Add(20*time.Second) {
+		t.Errorf("deadline = %v", payload["deadline"])
+	}
+}
+
+func TestGameSettingsKeyTTL(t *testing.T) {
+	if got := (GameSettings{}).keyTTL(); got != 24*time.Hour {
+		t.Errorf("live keyTTL = %v, want 24h", got)
+	}
+	closes := time.Now().Add(7 * 24 * time.Hour)
+	got := GameSettings{Mode: models.SessionModeSelfPaced, ClosesAt: &closes}.keyTTL()
+	if got < 8*24*time.Hour-time.Minute || got > 8*24*time.Hour {
+		t.Errorf("self-paced keyTTL = %v, want about 8 days", got)
+	}
+}
+
+func TestSelectEliminations(t *testing.T) {
+	survivors := []survivorResult{
+		{PlayerID: "a", Answered: true, IsCorrect: true, TotalScore: 900},
+		{PlayerID: "b", Answered: true, IsCorrect: false, TotalScore: 700},
+		{PlayerID: "c", Answered: false, TotalScore: 400},
+		{PlayerID: "d", Answered: true, IsCorrect: true, TotalScore: 400},
+		{PlayerID: "e", Answered: true, IsCorrect: true, TotalScore: 100},
+	}
+
+	tests := []struct {
+		name      string
+		mode      models.EliminationMode
+		count     int
+		survivors []survivorResult
+		want      []string
+	}{
+		{"off", models.EliminationOff, 1, survivors, nil},
+		{"wrong or missing answer", models.EliminationWrongAnswer, 1, survivors, []string{"b", "c"}},
+		{"everyone wrong spares all", models.EliminationWrongAnswer, 1, survivors[1:3], nil},
+		{"bottom one", models.EliminationBottom, 1, survivors, []string{"e"}},
+		{"bottom two splits tie, spares it", models.EliminationBottom, 2, survivors, []string{"e"}},
+		{"bottom three takes whole tie", models.EliminationBottom, 3, survivors, []string{"c", "d", "e"}},
+		{"bottom never empties the game", models.EliminationBottom, 10, survivors[:2], []string{"b"}},
+		{"last survivor stays", models.EliminationBottom, 1, survivors[:1], nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := selectEliminations(tc.mode, tc.count, tc.survivors)
+			if len(got) != len(tc.want) {
+				t.Fatalf("got %v, want %v", got, tc.want)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Fatalf("got %v, want %v", got, tc.want)
+				}
+			}
+		})
+	}
+}
+
+func TestDecideEliminations(t *testing.T) {
+	survivors := []survivorResult{
+		{PlayerID: "a", Answered: true, IsCorrect: true},
+		{PlayerID: "b", Answered: true, IsCorrect: false},
+		{PlayerID: "c", Answered: false},
+	}
+	state := &GameState{
+		CurrentIndex: 4,
+		Settings:     GameSettings{Elimination: models.EliminationLives, Lives: 3},
+		Lives:        map[string]int{"c": 1},
+	}
+
+	result := decideEliminations(state, storedQuestion{}, survivors)
+	if !slices.Equal(result.LivesLost, []string{"b", "c"}) || !slices.Equal(result.Eliminated, []string{"c"}) {
+		t.Fatalf("lost %v, eliminated %v; want [b c], [c]", result.LivesLost, result.Eliminated)
+	}
+	if result.Lives["a"] != 3 || result.Lives["b"] != 2 || result.Lives["c"] != 0 {
+		t.Errorf("lives = %v, want a 3, b 2, c 0", result.Lives)
+	}
+	if idx, out := state.Eliminated["c"]; !out || idx != 4 {
+		t.Errorf("state eliminated = %v, want c on question 4", state.Eliminated)
+	}
+}
+
+func TestLoseLives(t *testing.T) {
+	survivors := []survivorResult{
+		{PlayerID: "a", Answered: true, IsCorrect: true},
+		{PlayerID: "b", Answered: true, IsCorrect: false},
+		{PlayerID: "c", Answered: false},
+	}
+
+	lives := map[string]int{"c": 1}
+	lost, out := loseLives(survivors, lives, 3)
+	if !slices.Equal(lost, []string{"b", "c"}) || !slices.Equal(out, []string{"c"}) {
+		t.Fatalf("lost %v, out %v; want [b c], [c]", lost, out)
+	}
+	if lives["b"] != 2 || lives["c"] != 0 {
+		t.Errorf("lives = %v, want b 2, c 0", lives)
+	}
+	if _, ok := lives["a"]; ok {
+		t.Error("a answered correctly and should keep starting lives")
+	}
+
+	// Everyone left would go out, so nobody loses a life.
+	lives = map[string]int{"b": 1, "c": 1}
+	lost, out = loseLives(survivors[1:], lives, 3)
+	if lost != nil || out != nil || lives["b"] != 1 || lives["c"] != 1 {
+		t.Errorf("lost %v, out %v, lives %v; want no change", lost, out, lives)
+	}
+}
+
+func TestLivesLeft(t *testing.T) {
+	state := &GameState{Settings: GameSettings{Lives: 3}, Lives: map[string]int{"b": 1}}
+	if got := state.livesLeft("a"); got != 3 {
+		t.Errorf("livesLeft(a) = %d, want starting lives 3", got)
+	}
+	if got := state.livesLeft("b"); got != 1 {
+		t.Errorf("livesLeft(b) = %d, want 1", got)
+	}
+}
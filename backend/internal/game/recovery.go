@@ -0,0 +1,212 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/HassanA01/Iftarootv2/backend/internal/models"
+)
+
+// restartClaim is how long a node holds the claim to restart an orphaned
+// session, so that nodes booting together do not both restart it.
+const restartClaim = time.Minute
+
+// startingTTL is how long a session stays marked as starting if the node
+// starting it stops before it can clear the mark.
+const startingTTL = 24 * time.Hour
+
+// redisKeyStarting returns the Redis key marking a session whose game is
+// being started but has not been saved yet.
+func redisKeyStarting(code string) string { return fmt.Sprintf("game:%s:starting", code) }
+
+// redisKeyRestart returns the Redis key claimed by the node restarting a
+// session.
+func redisKeyRestart(code string) string { return fmt.Sprintf("game:%s:restart", code) }
+
+// Recover picks up games left in flight by a restart. Each game in Redis is
+// reconciled with its session's status, and its next transition is scheduled
+// if no timer for it is pending. Active sessions that never got as far as
+// saving game state are started again, or finished if play had begun.
+func (e *Engine) Recover(ctx context.Context) error {
+	codes := make(map[string]bool)
+	iter := e.redis.Scan(ctx, 0, redisKeyState("*"), 100).Iterator()
+	for iter.Next(ctx) {
+		code := strings.TrimSuffix(strings.TrimPrefix(iter.Val(), "game:"), ":state")
+		codes[code] = true
+		if err := e.recoverGame(ctx, code); err != nil {
+			log.Printf("engine: recover %s: %v", code, err)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	return e.recoverUnstarted(ctx, codes)
+}
+
+// recoverGame reconciles one game's Redis state with its session row and
+// reschedules whatever the game is waiting on.
+func (e *Engine) recoverGame(ctx context.Context, sessionCode string) error {
+	state, err := e.loadState(ctx, sessionCode)
+	if err != nil {
+		return err
+	}
+
+	var status models.GameStatus
+	err = e.db.QueryRow(ctx, `SELECT status FROM game_sessions WHERE id = $1`, state.SessionID).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		e.clearGame(ctx, sessionCode) // session deleted
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if status == models.GameStatusFinished && state.Phase != PhaseGameOver {
+		e.clearGame(ctx, sessionCode) // ended by the host
+		return nil
+	}
+	if state.Phase == PhaseGameOver && status != models.GameStatusFinished {
+		_, err := e.db.Exec(ctx,
+			`UPDATE game_sessions SET status = 'finished', ended_at = COALESCE(ended_at, NOW()) WHERE id = $1`,
+			state.SessionID,
+		)
+		return err
+	}
+
+	t, at, ok := nextTransition(state, time.Now())
+	if !ok {
+		return nil
+	}
+	return e.scheduleMissing(ctx, t, at)
+}
+
+// nextTransition returns the timer a game in state needs to move on without
+// the host, and when it is due, judged at now. ok is false when the game is
+// waiting on the host or has ended.
+func nextTransition(state *GameState, now time.Time) (t timer, at time.Time, ok bool) {
+	t = timer{Code: state.SessionCode, Run: state.QuestionRun}
+	switch state.Phase {
+	case PhaseStarting:
+		t.Kind, at = timerStart, now.Add(StartCountdownSeconds*time.Second)
+	case PhaseWager:
+		t.Kind, at = timerWager, state.WagerDeadline
+	case PhaseQuestion:
+		if state.Paused {
+			return timer{}, time.Time{}, false
+		}
+		t.Kind, t.Index, at = timerQuestion, state.CurrentIndex, state.QuestionDeadline
+	case PhaseBuzzerFloor:
+		t.Kind, t.PlayerID, at = timerBuzzer, state.BuzzerPlayer, state.BuzzerDeadline
+	case PhaseReveal:
+		if !state.Scored {
+			// Scoring stopped part way; give a node still at it time to
+			// finish before scoring again.
+			t.Kind, at = timerReveal, now.Add(RevealSeconds*time.Second)
+			break
+		}
+		t.Kind, at = timerLeaderboard, now.Add(RevealSeconds*time.Second)
+	case PhaseLeaderboard, PhaseIntermission:
+		if !state.Settings.AutoAdvance {
+			return timer{}, time.Time{}, false
+		}
+		t.Kind, at = timerAdvance, now.Add(time.Duration(state.Settings.AutoAdvanceDelay)*time.Second)
+	case PhaseSuddenDeath:
+		if len(state.SuddenDeathPlayers) > 0 {
+			t.Kind, at = timerSuddenDeath, state.QuestionDeadline
+		} else {
+			t.Kind, at = timerSuddenDeathResult, now.Add(SuddenDeathResultSeconds*time.Second)
+		}
+	case PhaseSelfPaced:
+		if state.Settings.ClosesAt == nil {
+			return timer{}, time.Time{}, false
+		}
+		t.Kind, at = timerSelfPacedClose, *state.Settings.ClosesAt
+	default:
+		return timer{}, time.Time{}, false
+	}
+	return t, at, true
+}
+
+// scheduleMissing schedules t unless a timer of its kind is already pending
+// or claimed for the session, as it is when the node that set it is still
+// running or a lease on it will run out.
+func (e *Engine) scheduleMissing(ctx context.Context, t timer, at time.Time) error {
+	for _, key := range []string{redisKeyTimers, redisKeyTimerLeased} {
+		err := e.redis.ZScore(ctx, key, timerID(t.Code, t.Kind)).Err()
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, redis.Nil) {
+			return err
+		}
+	}
+	return e.schedule(ctx, t, at)
+}
+
+// recoverUnstarted handles active sessions with no game in Redis: the host
+// started them but the game never saved its state, or the state was lost.
+// Sessions still marked as starting where nobody has answered yet are started
+// again; sessions where play had begun cannot be resumed and are finished.
+// Others are left alone, as are sessions started in the last few seconds,
+// since another node may still be starting them.
+func (e *Engine) recoverUnstarted(ctx context.Context, running map[string]bool) error {
+	rows, err := e.db.Query(ctx,
+		`SELECT s.id::text, s.code, s.quiz_id::text,
+		        EXISTS (SELECT 1 FROM game_answers a WHERE a.session_id = s.id)
+		 FROM game_sessions s
+		 WHERE s.status = 'active' AND s.started_at < NOW() - INTERVAL '10 seconds'`,
+	)
+	if err != nil {
+		return err
+	}
+	type session struct {
+		id, code, quizID string
+		answered         bool
+	}
+	var orphaned []session
+	for rows.Next() {
+		var s session
+		if err := rows.Scan(&s.id, &s.code, &s.quizID, &s.answered); err != nil {
+			rows.Close()
+			return err
+		}
+		if !running[s.code] {
+			orphaned = append(orphaned, s)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, s := range orphaned {
+		if !s.answered {
+			starting, err := e.redis.Exists(ctx, redisKeyStarting(s.code)).Result()
+			if err != nil || starting == 0 {
+				continue
+			}
+		}
+		claimed, err := e.redis.SetNX(ctx, redisKeyRestart(s.code), 1, restartClaim).Result()
+		if err != nil || !claimed {
+			continue
+		}
+		if !s.answered {
+			if err := e.StartGame(ctx, s.code, s.id, s.quizID); err != nil {
+				log.Printf("engine: restart %s: %v", s.code, err)
+			}
+			continue
+		}
+		if _, err := e.db.Exec(ctx,
+			`UPDATE game_sessions SET status = 'finished', ended_at = NOW() WHERE id = $1`, s.id,
+		); err != nil {
+			log.Printf("engine: finish %s: %v", s.code, err)
+		}
+	}
+	return nil
+}
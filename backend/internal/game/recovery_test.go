@@ -0,0 +1,97 @@
+package game
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNextTransition(t *testing.T) {
+	now := time.Date(2026, 3, 20, 19, 0, 0, 0, time.UTC)
+	deadline := now.Add(12 * time.Second)
+	closesAt := now.Add(time.Hour)
+
+	tests := []struct {
+		name     string
+		state    GameState
+		wantKind timerKind
+		wantAt   time.Time
+		wantOK   bool
+	}{
+		{"starting", GameState{Phase: PhaseStarting}, timerStart, now.Add(StartCountdownSeconds * time.Second), true},
+		{"open question", GameState{Phase: PhaseQuestion, QuestionDeadline: deadline}, timerQuestion, deadline, true},
+		{"paused question", GameState{Phase: PhaseQuestion, Paused: true}, "", time.Time{}, false},
+		{"wager", GameState{Phase: PhaseWager, WagerDeadline: deadline}, timerWager, deadline, true},
+		{"buzzer floor", GameState{Phase: PhaseBuzzerFloor, BuzzerPlayer: "p1", BuzzerDeadline: deadline}, timerBuzzer, deadline, true},
+		{"buzzer judge", GameState{Phase: PhaseBuzzerJudge}, "", time.Time{}, false},
+		{"reveal", GameState{Phase: PhaseReveal, Scored: true}, timerLeaderboard, now.Add(RevealSeconds * time.Second), true},
+		{"unfinished reveal", GameState{Phase: PhaseReveal}, timerReveal, now.Add(RevealSeconds * time.Second), true},
+		{"hosted leaderboard", GameState{Phase: PhaseLeaderboard}, "", time.Time{}, false},
+		{"auto-advancing leaderboard", GameState{Phase: PhaseLeaderboard, Settings: GameSettings{AutoAdvance: true, AutoAdvanceDelay: 8}}, timerAdvance, now.Add(8 * time.Second), true},
+		{"sudden death open", GameState{Phase: PhaseSuddenDeath, SuddenDeathPlayers: []string{"a", "b"}, QuestionDeadline: deadline}, timerSuddenDeath, deadline, true},
+		{"sudden death result", GameState{Phase: PhaseSuddenDeath}, timerSuddenDeathResult, now.Add(SuddenDeathResultSeconds * time.Second), true},
+		{"self-paced", GameState{Phase: PhaseSelfPaced, Settings: GameSettings{ClosesAt: &closesAt}}, timerSelfPacedClose, closesAt, true},
+		{"game over", GameState{Phase: PhaseGameOver}, "", time.Time{}, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.state.SessionCode = "123456"
+			tm, at, ok := nextTransition(&tc.state, now)
+			if ok != tc.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tc.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if tm.Kind != tc.wantKind || !at.Equal(tc.wantAt) || tm.Code != "123456" {
+				t.Errorf("got %s at %v for %s, want %s at %v", tm.Kind, at, tm.Code, tc.wantKind, tc.wantAt)
+			}
+		})
+	}
+}
+
+func TestScheduleMissing(t *testing.T) {
+	e := newRedisEngine(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	// A timer still pending from before the restart is left as it is.
+	_ = e.schedule(ctx, timer{Code: "555555", Kind: timerQuestion, Index: 4}, now.Add(5*time.Second))
+	if err := e.scheduleMissing(ctx, timer{Code: "555555", Kind: timerQuestion, Index: 4}, now); err != nil {
+		t.Fatal(err)
+	}
+	if due, _ := e.claimDueTimers(ctx, now); len(due) != 0 {
+		t.Errorf("pending timer was rescheduled: %+v", due)
+	}
+
+	if err := e.scheduleMissing(ctx, timer{Code: "666666", Kind: timerLeaderboard, Run: 2}, now); err != nil {
+		t.Fatal(err)
+	}
+	if due, _ := e.claimDueTimers(ctx, now); len(due) != 1 || due[0].Run != 2 {
+		t.Errorf("claimed %+v, want the missing leaderboard timer", due)
+	}
+}
+
+func TestFinishRevealLeavesScoredReveals(t *testing.T) {
+	e := newRedisEngine(t)
+	ctx := context.Background()
+	state := &GameState{SessionCode: "777777", Phase: PhaseReveal, QuestionRun: 3, Scored: true}
+	if err := e.saveState(ctx, "777777", state); err != nil {
+		t.Fatal(err)
+	}
+
+	// Scored reveals, and reveals of an earlier run, are not scored again;
+	// scoring would need the database, which this engine lacks.
+	if err := e.finishReveal(ctx, "777777", 3); err != nil {
+		t.Errorf("scored reveal: %v", err)
+	}
+	state.Scored = false
+	state.QuestionRun = 4
+	if err := e.saveState(ctx, "777777", state); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.finishReveal(ctx, "777777", 3); err != nil {
+		t.Errorf("stale run: %v", err)
+	}
+}
@@ -0,0 +1,254 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/HassanA01/Iftarootv2/backend/internal/hub"
+	"github.com/HassanA01/Iftarootv2/backend/internal/models"
+)
+
+// RoundInfo locates a round within the session's question list.
+type RoundInfo struct {
+	Title string `json:"title"`
+	Start int    `json:"start"` // index of the round's first question
+	Count int    `json:"count"`
+}
+
+// sessionRound is a round as configured on the session, with its quiz and
+// scoring strategy already resolved.
+type sessionRound struct {
+	Title            string
+	QuizID           string
+	QuestionCount    int
+	ScoringStrategy  string
+	PointsMultiplier int
+}
+
+// roundIndex returns the round question idx belongs to, or 0 when the session
+// has no rounds.
+func roundIndex(rounds []RoundInfo, idx int) int {
+	for i := len(rounds) - 1; i > 0; i-- {
+		if idx >= rounds[i].Start {
+			return i
+		}
+	}
+	return 0
+}
+
+// startsRound reports whether idx opens any round after the first, so an
+// intermission comes before it.
+func (s *GameState) startsRound(idx int) bool {
+	for _, r := range s.Rounds[min(1, len(s.Rounds)):] {
+		if r.Start == idx {
+			return true
+		}
+	}
+	return false
+}
+
+// roundSummary describes round i for clients.
+func (s *GameState) roundSummary(i int) map[string]any {
+	r := s.Rounds[i]
+	return map[string]any{
+		"index":        i,
+		"title":        r.Title,
+		"total_rounds": len(s.Rounds),
+		"questions":    r.Count,
+	}
+}
+
+// addRound tells clients which round question idx is in, for sessions with
+// rounds.
+func (s *GameState) addRound(payload map[string]any, idx int) map[string]any {
+	if len(s.Rounds) == 0 {
+		return payload
+	}
+	i := roundIndex(s.Rounds, idx)
+	round := s.roundSummary(i)
+	round["question_in_round"] = idx - s.Rounds[i].Start + 1
+	payload["round"] = round
+	return payload
+}
+
+// loadRounds returns a session's rounds in play order, each with its quiz
+// and default scoring strategy resolved.
+func (e *Engine) loadRounds(ctx context.Context, sessionID string) ([]sessionRound, error) {
+	rows, err := e.db.Query(ctx,
+		`SELECT r.title, COALESCE(r.quiz_id, s.quiz_id)::text, COALESCE(r.question_count, 0),
+		        COALESCE(r.scoring_strategy, q.scoring_strategy), r.points_multiplier
+		 FROM game_session_rounds r
+		 JOIN game_sessions s ON s.id = r.session_id
+		 JOIN quizzes q ON q.id = COALESCE(r.quiz_id, s.quiz_id)
+		 WHERE r.session_id = $1
+		 ORDER BY r.position`,
+		sessionID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rounds []sessionRound
+	for rows.Next() {
+		var r sessionRound
+		if err := rows.Scan(&r.Title, &r.QuizID, &r.QuestionCount, &r.ScoringStrategy, &r.PointsMultiplier); err != nil {
+			return nil, err
+		}
+		rounds = append(rounds, r)
+	}
+	return rounds, rows.Err()
+}
+
+// roundQuestions assembles the questions for each round in turn, applying the
+// session's shuffle settings within a round and the round's scoring on top of
+// each question's. A question is asked at most once per session.
+func (e *Engine) roundQuestions(ctx context.Context, rounds []sessionRound, settings GameSettings) ([]storedQuestion, []RoundInfo, error) {
+	var questions []storedQuestion
+	var infos []RoundInfo
+	used := make(map[string]bool)
+	for i, r := range rounds {
+		picked, err := e.quizQuestions(ctx, r.QuizID)
+		if err != nil {
+			return nil, nil, err
+		}
+		picked = slices.DeleteFunc(picked, func(q storedQuestion) bool { return used[q.ID] })
+		roundSettings := settings
+		roundSettings.QuestionCount = r.QuestionCount
+		picked = arrangeQuestions(picked, roundSettings)
+		if len(picked) == 0 {
+			return nil, nil, fmt.Errorf("round %d (%s) has no questions", i+1, r.Title)
+		}
+		for j := range picked {
+			used[picked[j].ID] = true
+			m := roundMultiplier(picked[j].multiplier(), r.PointsMultiplier)
+			picked[j].PointsMultiplier = &m
+		}
+		resolveScoringStrategies(picked, r.ScoringStrategy)
+
+		infos = append(infos, RoundInfo{Title: r.Title, Start: len(questions), Count: len(picked)})
+		questions = append(questions, picked...)
+	}
+	return questions, infos, nil
+}
+
+// roundMultiplier combines a question's points multiplier with its round's.
+// Either being 0x makes the question worth nothing; otherwise the product is
+// capped at 2x, the most any question may score, so a 2x question in a 2x
+// round scores double rather than quadruple.
+func roundMultiplier(question, round int) int {
+	return min(question*round, 2)
+}
+
+// openIntermission pauses between rounds on the standings for the round just
+// finished, until the host starts the next one.
+func (e *Engine) openIntermission(ctx context.Context, sessionCode string, state *GameState) error {
+	state.Phase = PhaseIntermission
+	if err := e.saveState(ctx, sessionCode, state); err != nil {
+		return err
+	}
+
+	payload, err := e.intermissionPayload(ctx, state)
+	if err != nil {
+		return err
+	}
+	if state.Settings.AutoAdvance {
+		delay := time.Duration(state.Settings.AutoAdvanceDelay) * time.Second
+		payload["auto_advance_ms"] = delay.Milliseconds()
+		if err := e.schedule(ctx, timer{Code: sessionCode, Kind: timerAdvance, Run: state.QuestionRun}, time.Now().Add(delay)); err != nil {
+			return err
+		}
+	}
+	e.hub.Broadcast(sessionCode, hub.Message{
+		Type:    hub.MsgIntermission,
+		Payload: payload,
+	})
+	return nil
+}
+
+// StartNextRound ends the intermission and opens the first question of the
+// next round.
+func (e *Engine) StartNextRound(ctx context.Context, sessionCode string) error {
+	state, err := e.loadState(ctx, sessionCode)
+	if err != nil {
+		return err
+	}
+	if state.Phase != PhaseIntermission {
+		return fmt.Errorf("no round to start (current: %s)", state.Phase)
+	}
+	next := state.CurrentIndex + 1
+	e.hub.Broadcast(sessionCode, hub.Message{
+		Type:    hub.MsgRoundStarted,
+		Payload: state.roundSummary(roundIndex(state.Rounds, next)),
+	})
+	return e.broadcastQuestion(ctx, sessionCode, next)
+}
+
+// IntermissionMessage returns the intermission message for a client joining
+// between rounds, or nil if the game is not in an intermission.
+func (e *Engine) IntermissionMessage(ctx context.Context, sessionCode string) (*hub.Message, error) {
+	state, err := e.loadState(ctx, sessionCode)
+	if err != nil {
+		return nil, err
+	}
+	if state.Phase != PhaseIntermission {
+		return nil, nil
+	}
+	payload, err := e.intermissionPayload(ctx, state)
+	if err != nil {
+		return nil, err
+	}
+	return &hub.Message{Type: hub.MsgIntermission, Payload: payload}, nil
+}
+
+// intermissionPayload is the overall leaderboard plus the standings for the
+// round just finished and the title of the next.
+func (e *Engine) intermissionPayload(ctx context.Context, state *GameState) (map[string]any, error) {
+	payload, err := e.leaderboardPayload(ctx, state)
+	if err != nil {
+		return nil, err
+	}
+	round := roundIndex(state.Rounds, state.CurrentIndex)
+	standings, err := e.roundStandings(ctx, state.SessionID, round)
+	if err != nil {
+		return nil, err
+	}
+	payload["round"] = state.roundSummary(round)
+	payload["round_standings"] = standings
+	if round+1 < len(state.Rounds) {
+		payload["next_round"] = state.roundSummary(round + 1)
+	}
+	return payload, nil
+}
+
+// roundStandings ranks players by the points they scored in one round.
+func (e *Engine) roundStandings(ctx context.Context, sessionID string, round int) ([]models.RoundStandingEntry, error) {
+	rows, err := e.db.Query(ctx,
+		`SELECT p.id, p.name, COALESCE(SUM(a.points), 0) AS points
+		 FROM game_players p
+		 LEFT JOIN game_answers a ON a.player_id = p.id AND a.question_id IN (
+		     SELECT question_id FROM game_session_questions WHERE session_id = $1 AND round = $2
+		 )
+		 WHERE p.session_id = $1
+		 GROUP BY p.id, p.name
+		 ORDER BY points DESC, p.name`,
+		sessionID, round,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.RoundStandingEntry{}
+	for rows.Next() {
+		var s models.RoundStandingEntry
+		if err := rows.Scan(&s.PlayerID, &s.Name, &s.Points); err != nil {
+			return nil, err
+		}
+		s.Rank = len(entries) + 1
+		entries = append(entries, s)
+	}
+	return entries, rows.Err()
+}
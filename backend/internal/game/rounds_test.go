@@ -0,0 +1,61 @@
+package game
+
+import "testing"
+
+func TestRoundIndex(t *testing.T) {
+	rounds := []RoundInfo{
+		{Title: "Seerah", Start: 0, Count: 3},
+		{Title: "Fiqh", Start: 3, Count: 2},
+		{Title: "Lightning", Start: 5, Count: 4},
+	}
+	cases := []struct{ idx, want int }{{0, 0}, {2, 0}, {3, 1}, {4, 1}, {5, 2}, {8, 2}}
+	for _, c := range cases {
+		if got := roundIndex(rounds, c.idx); got != c.want {
+			t.Errorf("roundIndex(%d) = %d, want %d", c.idx, got, c.want)
+		}
+	}
+	if got := roundIndex(nil, 4); got != 0 {
+		t.Errorf("roundIndex without rounds = %d, want 0", got)
+	}
+}
+
+func TestStartsRound(t *testing.T) {
+	state := &GameState{Rounds: []RoundInfo{{Start: 0, Count: 2}, {Start: 2, Count: 2}}}
+	if state.startsRound(0) {
+		t.Error("the first round has no intermission before it")
+	}
+	if !state.startsRound(2) {
+		t.Error("question 2 opens the second round")
+	}
+	if state.startsRound(3) {
+		t.Error("question 3 is mid-round")
+	}
+	if (&GameState{}).startsRound(0) {
+		t.Error("games without rounds have no intermissions")
+	}
+}
+
+func TestAddRound(t *testing.T) {
+	payload := (&GameState{}).addRound(map[string]any{}, 0)
+	if _, ok := payload["round"]; ok {
+		t.Error("single-round games should not carry round info")
+	}
+
+	state := &GameState{Rounds: []RoundInfo{{Title: "One", Start: 0, Count: 2}, {Title: "Two", Start: 2, Count: 3}}}
+	round := state.addRound(map[string]any{}, 3)["round"].(map[string]any)
+	if round["index"] != 1 || round["title"] != "Two" || round["total_rounds"] != 2 ||
+		round["questions"] != 3 || round["question_in_round"] != 2 {
+		t.Errorf("round = %v", round)
+	}
+}
+
+func TestRoundMultiplier(t *testing.T) {
+	cases := []struct{ question, round, want int }{
+		{1, 1, 1}, {2, 1, 2}, {1, 2, 2}, {2, 2, 2}, {0, 2, 0}, {2, 0, 0},
+	}
+	for _, c := range cases {
+		if got := roundMultiplier(c.question, c.round); got != c.want {
+			t.Errorf("roundMultiplier(%d, %d) = %d, want %d", c.question, c.round, got, c.want)
+		}
+	}
+}
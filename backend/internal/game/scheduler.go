@@ -0,0 +1,274 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Timed transitions — the start countdown, question deadlines, wager and
+// buzzer windows, reveals, sudden death, auto-advance and self-paced closing —
+// are kept in Redis rather than in goroutines, so that any backend node can
+// fire them and they outlive the node that set them. Each node polls for due
+// timers. Claiming one leases it to that node, so no other node fires it
+// while the lease lasts; the timer is only removed once it has fired. If the
+// transition fails or the node dies, the lease runs out and whichever node
+// polls next claims the timer again.
+
+// redisKeyTimers is the sorted set of pending timers, scored by when they are
+// due in Unix milliseconds.
+const redisKeyTimers = "game:timers"
+
+// redisKeyTimerArgs is the hash holding each pending timer's details.
+const redisKeyTimerArgs = "game:timers:args"
+
+// redisKeyTimerLeased is the sorted set of claimed timers that have not yet
+// fired, scored by when their lease runs out in Unix milliseconds.
+const redisKeyTimerLeased = "game:timers:leased"
+
+// redisKeyTimerLeases is the hash holding each claimed timer's details.
+const redisKeyTimerLeases = "game:timers:leases"
+
+// timerLease is how long a node has to fire a timer it claimed before other
+// nodes may claim it again.
+const timerLease = 30 * time.Second
+
+// maxTimerAttempts bounds how often a timer is claimed before it is dropped
+// as unable to fire.
+const maxTimerAttempts = 5
+
+// timerPollInterval is how often a node checks for due timers.
+const timerPollInterval = 200 * time.Millisecond
+
+// timerClaimBatch bounds how many due timers a node claims per poll.
+const timerClaimBatch = 100
+
+// timerKind identifies the transition a timer fires.
+type timerKind string
+
+const (
+	timerStart             timerKind = "start"               // open the first question after the countdown
+	timerQuestion          timerKind = "question"            // reveal the question at Index
+	timerWager             timerKind = "wager"               // close the wager phase
+	timerBuzzer            timerKind = "buzzer"              // end PlayerID's turn on the floor
+	timerReveal            timerKind = "reveal"              // finish scoring a reveal that stopped part way
+	timerLeaderboard       timerKind = "leaderboard"         // show the leaderboard after a reveal
+	timerSuddenDeath       timerKind = "sudden_death"        // resolve the sudden-death question
+	timerSuddenDeathResult timerKind = "sudden_death_result" // finish after a sudden-death result
+	timerAdvance           timerKind = "advance"             // move on from the leaderboard or an intermission
+	timerSelfPacedClose    timerKind = "self_paced_close"    // close the self-paced window
+)
+
+var timerKinds = []timerKind{
+	timerStart, timerQuestion, timerWager, timerBuzzer, timerReveal, timerLeaderboard,
+	timerSuddenDeath, timerSuddenDeathResult, timerAdvance, timerSelfPacedClose,
+}
+
+// timer is a pending transition for a session. A session has at most one
+// timer of each kind; scheduling another replaces it. Run is the
+// GameState.QuestionRun the timer was set for, so a timer outlived by the
+// host moving on does nothing.
+type timer struct {
+	Code     string    `json:"code"`
+	Kind     timerKind `json:"kind"`
+	Run      int       `json:"run"`
+	Index    int       `json:"index,omitempty"`
+	PlayerID string    `json:"player_id,omitempty"`
+	Attempts int       `json:"attempts,omitempty"` // times claimed before this one
+
+	leasedUntil int64 // lease deadline in Unix milliseconds, once claimed
+}
+
+func timerID(code string, kind timerKind) string { return code + "|" + string(kind) }
+
+// claimTimersScript leases and returns the details of up to ARGV[2] timers
+// that are due by ARGV[1] or whose lease ran out by then, so that concurrent
+// pollers never claim the same timer. Leases run until ARGV[1] + ARGV[3]. A
+// timer whose lease runs out for the ARGV[4]th time is dropped.
+var claimTimersScript = redis.NewScript(`
+local now, limit = tonumber(ARGV[1]), tonumber(ARGV[2])
+local deadline = now + tonumber(ARGV[3])
+local claimed = {}
+local expired = redis.call('ZRANGEBYSCORE', KEYS[3], '-inf', now, 'LIMIT', 0, limit)
+for _, id in ipairs(expired) do
+	local args = redis.call('HGET', KEYS[4], id)
+	local t = args and cjson.decode(args)
+	if t and (t.attempts or 0) + 1 < tonumber(ARGV[4]) then
+		t.attempts = (t.attempts or 0) + 1
+		args = cjson.encode(t)
+		redis.call('ZADD', KEYS[3], deadline, id)
+		redis.call('HSET', KEYS[4], id, args)
+		table.insert(claimed, args)
+	else
+		redis.call('ZREM', KEYS[3], id)
+		redis.call('HDEL', KEYS[4], id)
+	end
+end
+if #claimed < limit then
+	local due = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', now, 'LIMIT', 0, limit - #claimed)
+	for _, id in ipairs(due) do
+		redis.call('ZREM', KEYS[1], id)
+		local args = redis.call('HGET', KEYS[2], id)
+		redis.call('HDEL', KEYS[2], id)
+		if args then
+			redis.call('ZADD', KEYS[3], deadline, id)
+			redis.call('HSET', KEYS[4], id, args)
+			table.insert(claimed, args)
+		end
+	end
+end
+return claimed
+`)
+
+// ackTimerScript removes the lease on timer ARGV[1] once it has fired, if
+// the lease is still the one that ran until ARGV[2].
+var ackTimerScript = redis.NewScript(`
+local leased = redis.call('ZSCORE', KEYS[1], ARGV[1])
+if leased and tonumber(leased) == tonumber(ARGV[2]) then
+	redis.call('ZREM', KEYS[1], ARGV[1])
+	redis.call('HDEL', KEYS[2], ARGV[1])
+end
+return 0
+`)
+
+// schedule sets t to fire at at, replacing any timer of the same kind for the
+// session.
+func (e *Engine) schedule(ctx context.Context, t timer, at time.Time) error {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	id := timerID(t.Code, t.Kind)
+	pipe := e.redis.TxPipeline()
+	pipe.HSet(ctx, redisKeyTimerArgs, id, data)
+	pipe.ZAdd(ctx, redisKeyTimers, redis.Z{Score: float64(at.UnixMilli()), Member: id})
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// unschedule cancels a session's pending timers of the given kinds, and any
+// of them claimed but not yet fired so that they are not claimed again.
+func (e *Engine) unschedule(ctx context.Context, sessionCode string, kinds ...timerKind) {
+	ids := make([]any, len(kinds))
+	fields := make([]string, len(kinds))
+	for i, kind := range kinds {
+		fields[i] = timerID(sessionCode, kind)
+		ids[i] = fields[i]
+	}
+	pipe := e.redis.TxPipeline()
+	pipe.ZRem(ctx, redisKeyTimers, ids...)
+	pipe.HDel(ctx, redisKeyTimerArgs, fields...)
+	pipe.ZRem(ctx, redisKeyTimerLeased, ids...)
+	pipe.HDel(ctx, redisKeyTimerLeases, fields...)
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("engine: unschedule error: %v", err)
+	}
+}
+
+// claimDueTimers leases and returns the timers due by now, along with those
+// whose lease ran out without them firing.
+func (e *Engine) claimDueTimers(ctx context.Context, now time.Time) ([]timer, error) {
+	raw, err := claimTimersScript.Run(ctx, e.redis,
+		[]string{redisKeyTimers, redisKeyTimerArgs, redisKeyTimerLeased, redisKeyTimerLeases},
+		strconv.FormatInt(now.UnixMilli(), 10), timerClaimBatch, timerLease.Milliseconds(), maxTimerAttempts,
+	).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+	leasedUntil := now.Add(timerLease).UnixMilli()
+	timers := make([]timer, 0, len(raw))
+	for _, data := range raw {
+		var t timer
+		if err := json.Unmarshal([]byte(data), &t); err != nil {
+			log.Printf("engine: bad timer %q: %v", data, err)
+			continue
+		}
+		t.leasedUntil = leasedUntil
+		timers = append(timers, t)
+	}
+	return timers, nil
+}
+
+// ackTimer removes the lease on a timer that has fired. A timer claimed
+// again after its lease ran out is left to the node that claimed it.
+func (e *Engine) ackTimer(ctx context.Context, t timer) error {
+	return ackTimerScript.Run(ctx, e.redis,
+		[]string{redisKeyTimerLeased, redisKeyTimerLeases},
+		timerID(t.Code, t.Kind), t.leasedUntil,
+	).Err()
+}
+
+// Run fires due timers until ctx is done. Timers that fell due while no node
+// was running, such as across a restart, fire on the first poll, and timers
+// another node claimed but never fired are picked up once their lease runs
+// out.
+func (e *Engine) Run(ctx context.Context) {
+	ticker := time.NewTicker(timerPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			timers, err := e.claimDueTimers(ctx, now)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Printf("engine: claim timers error: %v", err)
+				}
+				continue
+			}
+			for _, t := range timers {
+				go e.fire(t)
+			}
+		}
+	}
+}
+
+// fire runs the transition for t and releases its lease. Each transition
+// checks the game is still where the timer left it, so a timer fired again
+// after its lease ran out does nothing if it had taken effect. If the
+// transition fails the lease is kept, and the timer is claimed again once it
+// runs out.
+func (e *Engine) fire(t timer) {
+	if err := retryOnConflict(func() error { return e.fireOnce(t) }); err != nil {
+		log.Printf("engine: %s timer error (attempt %d): %v", t.Kind, t.Attempts+1, err)
+		return
+	}
+	if err := e.ackTimer(context.Background(), t); err != nil {
+		log.Printf("engine: ack %s timer error: %v", t.Kind, err)
+	}
+}
+
+func (e *Engine) fireOnce(t timer) error {
+	ctx := context.Background()
+	var err error
+	switch t.Kind {
+	case timerStart:
+		err = e.openFirstQuestion(ctx, t.Code)
+	case timerQuestion:
+		err = e.questionTimeUp(ctx, t.Code, t.Index)
+	case timerWager:
+		err = e.closeWager(ctx, t.Code, t.Run)
+	case timerBuzzer:
+		err = e.closeBuzzerWindow(ctx, t.Code, t.Run, t.PlayerID)
+	case timerReveal:
+		err = e.finishReveal(ctx, t.Code, t.Run)
+	case timerLeaderboard:
+		err = e.broadcastLeaderboard(ctx, t.Code, t.Run)
+	case timerSuddenDeath:
+		err = e.resolveSuddenDeath(ctx, t.Code, t.Run)
+	case timerSuddenDeathResult:
+		err = e.closeSuddenDeathResult(ctx, t.Code, t.Run)
+	case timerAdvance:
+		err = e.autoAdvance(ctx, t.Code, t.Run)
+	case timerSelfPacedClose:
+		err = e.closeSelfPaced(ctx, t.Code)
+	default:
+		log.Printf("engine: unknown timer kind %q", t.Kind)
+	}
+	return err
+}
@@ -0,0 +1,200 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// newRedisEngine returns an engine backed by an in-memory Redis and no
+// database.
+func newRedisEngine(t *testing.T) *Engine {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	return NewEngine(nil, nil, rdb)
+}
+
+func TestScheduleAndClaim(t *testing.T) {
+	e := newRedisEngine(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	if err := e.schedule(ctx, timer{Code: "111111", Kind: timerQuestion, Index: 2}, now.Add(-time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.schedule(ctx, timer{Code: "111111", Kind: timerWager, Run: 3}, now.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	due, err := e.claimDueTimers(ctx, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(due) != 1 || due[0].Kind != timerQuestion || due[0].Index != 2 {
+		t.Fatalf("claimed %+v, want the question timer for index 2", due)
+	}
+	if again, _ := e.claimDueTimers(ctx, now); len(again) != 0 {
+		t.Errorf("timer claimed twice: %+v", again)
+	}
+	if err := e.ackTimer(ctx, due[0]); err != nil {
+		t.Fatal(err)
+	}
+	if later, _ := e.claimDueTimers(ctx, now.Add(2*time.Minute)); len(later) != 1 || later[0].Kind != timerWager {
+		t.Errorf("claimed %+v, want the wager timer once due", later)
+	}
+}
+
+func TestScheduleReplacesSameKind(t *testing.T) {
+	e := newRedisEngine(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	// Extending the question pushes its deadline back.
+	_ = e.schedule(ctx, timer{Code: "222222", Kind: timerQuestion, Index: 0}, now)
+	_ = e.schedule(ctx, timer{Code: "222222", Kind: timerQuestion, Index: 0}, now.Add(30*time.Second))
+	if due, _ := e.claimDueTimers(ctx, now.Add(time.Second)); len(due) != 0 {
+		t.Errorf("replaced timer fired early: %+v", due)
+	}
+	if due, _ := e.claimDueTimers(ctx, now.Add(time.Minute)); len(due) != 1 {
+		t.Errorf("claimed %d timers, want 1", len(due))
+	}
+}
+
+func TestUnschedule(t *testing.T) {
+	e := newRedisEngine(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	_ = e.schedule(ctx, timer{Code: "333333", Kind: timerQuestion}, now)
+	_ = e.schedule(ctx, timer{Code: "333333", Kind: timerAdvance, Run: 1}, now)
+	_ = e.schedule(ctx, timer{Code: "444444", Kind: timerQuestion}, now)
+	e.unschedule(ctx, "333333", timerKinds...)
+
+	due, err := e.claimDueTimers(ctx, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(due) != 1 || due[0].Code != "444444" {
+		t.Errorf("claimed %+v, want only the other session's timer", due)
+	}
+}
+
+func TestClaimDueTimersOnce(t *testing.T) {
+	e := newRedisEngine(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	const sessions = 50
+	for i := range sessions {
+		if err := e.schedule(ctx, timer{Code: fmt.Sprintf("%06d", i), Kind: timerQuestion}, now); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Several nodes polling at once claim each timer exactly once.
+	var mu sync.Mutex
+	claimed := make(map[string]int)
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			due, err := e.claimDueTimers(ctx, now)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for _, tm := range due {
+				claimed[tm.Code]++
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(claimed) != sessions {
+		t.Errorf("claimed timers for %d sessions, want %d", len(claimed), sessions)
+	}
+	for code, n := range claimed {
+		if n != 1 {
+			t.Errorf("session %s claimed %d times", code, n)
+		}
+	}
+}
+
+func TestExpiredLeaseIsClaimedAgain(t *testing.T) {
+	e := newRedisEngine(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	_ = e.schedule(ctx, timer{Code: "555555", Kind: timerLeaderboard, Run: 1}, now)
+	first, err := e.claimDueTimers(ctx, now)
+	if err != nil || len(first) != 1 {
+		t.Fatalf("claimed %+v, %v", first, err)
+	}
+
+	// The node that claimed it never fired it; once the lease runs out
+	// another node claims it.
+	if due, _ := e.claimDueTimers(ctx, now.Add(timerLease/2)); len(due) != 0 {
+		t.Errorf("timer claimed while leased: %+v", due)
+	}
+	expired := now.Add(timerLease + time.Millisecond)
+	second, err := e.claimDueTimers(ctx, expired)
+	if err != nil || len(second) != 1 || second[0].Run != 1 || second[0].Attempts != 1 {
+		t.Fatalf("claimed %+v, %v, want the leaderboard timer on its second attempt", second, err)
+	}
+
+	// The first node acknowledging late leaves the new lease alone.
+	_ = e.ackTimer(ctx, first[0])
+	if n, _ := e.redis.ZCard(ctx, redisKeyTimerLeased).Result(); n != 1 {
+		t.Errorf("%d leases after a stale ack, want 1", n)
+	}
+	if err := e.ackTimer(ctx, second[0]); err != nil {
+		t.Fatal(err)
+	}
+	if due, _ := e.claimDueTimers(ctx, expired.Add(2*timerLease)); len(due) != 0 {
+		t.Errorf("fired timer claimed again: %+v", due)
+	}
+}
+
+func TestTimerDroppedAfterMaxAttempts(t *testing.T) {
+	e := newRedisEngine(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	_ = e.schedule(ctx, timer{Code: "666666", Kind: timerAdvance}, now)
+	claims := 0
+	for i := range maxTimerAttempts + 2 {
+		due, err := e.claimDueTimers(ctx, now.Add(time.Duration(i)*(timerLease+time.Millisecond)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		claims += len(due)
+	}
+	if claims != maxTimerAttempts {
+		t.Errorf("claimed %d times, want %d", claims, maxTimerAttempts)
+	}
+}
+
+func TestUnscheduleClearsLease(t *testing.T) {
+	e := newRedisEngine(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	_ = e.schedule(ctx, timer{Code: "777777", Kind: timerQuestion}, now)
+	if due, _ := e.claimDueTimers(ctx, now); len(due) != 1 {
+		t.Fatalf("claimed %d timers, want 1", len(due))
+	}
+	e.unschedule(ctx, "777777", timerQuestion)
+	if due, _ := e.claimDueTimers(ctx, now.Add(2*timerLease)); len(due) != 0 {
+		t.Errorf("cancelled timer claimed again: %+v", due)
+	}
+}
@@ -8,6 +8,23 @@ const (
 	StreakBonus = 100
 )
 
+// DefaultStreakSchedule is used when a quiz does not configure its own.
+// Entry n is the bonus for the (n+1)th consecutive correct answer.
+var DefaultStreakSchedule = []int{0, StreakBonus, 2 * StreakBonus, 3 * StreakBonus}
+
+// StreakBonusFor returns the bonus earned by a correct answer that brings the
+// player's streak to streak. Streaks longer than the schedule earn its last
+// entry. An empty schedule falls back to DefaultStreakSchedule.
+func StreakBonusFor(streak int, schedule []int) int {
+	if streak <= 0 {
+		return 0
+	}
+	if len(schedule) == 0 {
+		schedule = DefaultStreakSchedule
+	}
+	return schedule[min(streak, len(schedule))-1]
+}
+
 // CalculatePoints returns points for a correct answer.
 // elapsed is seconds taken to answer, timeLimit is the question time limit.
 // Faster answers score closer to BasePoints; minimum is MinPoints.
@@ -22,3 +39,80 @@ func CalculatePoints(elapsed float64, timeLimit int) int {
 	}
 	return points
 }
+
+// CalculatePartialPoints scales CalculatePoints by credit, the fraction of the
+// answer that was correct (clamped to [0, 1]).
+func CalculatePartialPoints(elapsed float64, timeLimit int, credit float64) int {
+	if credit <= 0 {
+		return MinPoints
+	}
+	if credit > 1 {
+		credit = 1
+	}
+	return int(math.Round(float64(CalculatePoints(elapsed, timeLimit)) * credit))
+}
+
+// MultiSelectCredit returns the fraction of credit earned for a multi-select
+// answer. Without partial credit only an exact match earns 1. With partial
+// credit each correct selection earns 1/len(correct) and each wrong selection
+// cancels one correct selection, floored at 0.
+func MultiSelectCredit(selected, correct []string, partial bool) float64 {
+	if len(correct) == 0 {
+		return 0
+	}
+	isCorrect := make(map[string]bool, len(correct))
+	for _, id := range correct {
+		isCorrect[id] = true
+	}
+	hits, misses := 0, 0
+	for _, id := range selected {
+		if isCorrect[id] {
+			hits++
+		} else {
+			misses++
+		}
+	}
+	if hits == len(correct) && misses == 0 {
+		return 1
+	}
+	if !partial {
+		return 0
+	}
+	return math.Max(0, float64(hits-misses)/float64(len(correct)))
+}
+
+// NumericCredit returns the fraction of credit earned by a numeric guess.
+// An exact guess earns 1. With a positive tolerance, credit falls linearly to
+// 0.5 at the edge of the tolerance; anything further away earns 0.
+func NumericCredit(guess, target, tolerance float64) float64 {
+	d := math.Abs(guess - target)
+	if d == 0 {
+		return 1
+	}
+	if tolerance <= 0 || d > tolerance {
+		return 0
+	}
+	return 1 - 0.5*(d/tolerance)
+}
+
+// OrderingCredit returns the fraction of credit earned for an ordering answer.
+// Without partial credit only the exact sequence earns 1. With partial credit
+// each option placed in its correct position earns 1/len(correct).
+func OrderingCredit(submitted, correct []string, partial bool) float64 {
+	if len(correct) == 0 {
+		return 0
+	}
+	hits := 0
+	for i, id := range correct {
+		if i < len(submitted) && submitted[i] == id {
+			hits++
+		}
+	}
+	if hits == len(correct) && len(submitted) == len(correct) {
+		return 1
+	}
+	if !partial {
+		return 0
+	}
+	return float64(hits) / float64(len(correct))
+}
@@ -1,6 +1,9 @@
 package game
 
-import "testing"
+import (
+	"math"
+	"testing"
+)
 
 func TestCalculatePoints(t *testing.T) {
 	tests := []struct {
@@ -26,3 +29,113 @@ func TestCalculatePoints(t *testing.T) {
 		})
 	}
 }
+
+func TestMultiSelectCredit(t *testing.T) {
+	correct := []string{"a", "b", "c"}
+	tests := []struct {
+		name     string
+		selected []string
+		partial  bool
+		want     float64
+	}{
+		{"exact match", []string{"c", "a", "b"}, false, 1},
+		{"exact match partial", []string{"a", "b", "c"}, true, 1},
+		{"subset without partial", []string{"a", "b"}, false, 0},
+		{"subset with partial", []string{"a", "b"}, true, 2.0 / 3.0},
+		{"wrong pick cancels a hit", []string{"a", "b", "x"}, true, 1.0 / 3.0},
+		{"more wrong than right floors at zero", []string{"a", "x", "y"}, true, 0},
+		{"superset without partial", []string{"a", "b", "c", "x"}, false, 0},
+		{"nothing selected", nil, true, 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := MultiSelectCredit(tc.selected, correct, tc.partial)
+			if math.Abs(got-tc.want) > 1e-9 {
+				t.Errorf("MultiSelectCredit(%v) = %v, want %v", tc.selected, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCalculatePartialPoints(t *testing.T) {
+	if got := CalculatePartialPoints(0, 20, 0.5); got != BasePoints/2 {
+		t.Errorf("half credit at instant answer: got %d, want %d", got, BasePoints/2)
+	}
+	if got := CalculatePartialPoints(0, 20, 0); got != MinPoints {
+		t.Errorf("zero credit: got %d, want %d", got, MinPoints)
+	}
+	if got := CalculatePartialPoints(0, 20, 2); got != BasePoints {
+		t.Errorf("credit above 1 should clamp: got %d, want %d", got, BasePoints)
+	}
+}
+
+func TestNumericCredit(t *testing.T) {
+	tests := []struct {
+		name               string
+		guess, target, tol float64
+		want               float64
+	}{
+		{"exact", 622, 622, 0, 1},
+		{"exact with tolerance", 622, 622, 10, 1},
+		{"off without tolerance", 623, 622, 0, 0},
+		{"halfway into tolerance", 627, 622, 10, 0.75},
+		{"edge of tolerance", 612, 622, 10, 0.5},
+		{"outside tolerance", 640, 622, 10, 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := NumericCredit(tc.guess, tc.target, tc.tol)
+			if math.Abs(got-tc.want) > 1e-9 {
+				t.Errorf("NumericCredit(%v, %v, %v) = %v, want %v", tc.guess, tc.target, tc.tol, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestOrderingCredit(t *testing.T) {
+	correct := []string{"a", "b", "c", "d"}
+	tests := []struct {
+		name      string
+		submitted []string
+		partial   bool
+		want      float64
+	}{
+		{"exact", []string{"a", "b", "c", "d"}, false, 1},
+		{"swap without partial", []string{"a", "b", "d", "c"}, false, 0},
+		{"swap with partial", []string{"a", "b", "d", "c"}, true, 0.5},
+		{"reversed with partial", []string{"d", "c", "b", "a"}, true, 0},
+		{"short submission", []string{"a", "b"}, true, 0.5},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := OrderingCredit(tc.submitted, correct, tc.partial)
+			if math.Abs(got-tc.want) > 1e-9 {
+				t.Errorf("OrderingCredit(%v) = %v, want %v", tc.submitted, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestStreakBonusFor(t *testing.T) {
+	tests := []struct {
+		name     string
+		streak   int
+		schedule []int
+		want     int
+	}{
+		{"no streak", 0, nil, 0},
+		{"first correct earns nothing by default", 1, nil, 0},
+		{"second in a row", 2, nil, StreakBonus},
+		{"default caps at last entry", 10, nil, 3 * StreakBonus},
+		{"custom schedule", 2, []int{50, 150}, 150},
+		{"custom schedule repeats last entry", 5, []int{50, 150}, 150},
+		{"disabled", 4, []int{0}, 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := StreakBonusFor(tc.streak, tc.schedule); got != tc.want {
+				t.Errorf("StreakBonusFor(%d, %v) = %d, want %d", tc.streak, tc.schedule, got, tc.want)
+			}
+		})
+	}
+}
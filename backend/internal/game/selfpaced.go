@@ -0,0 +1,420 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/HassanA01/Iftarootv2/backend/internal/hub"
+	"github.com/HassanA01/Iftarootv2/backend/internal/models"
+)
+
+// MaxSelfPacedWindow bounds how long a self-paced session can stay open.
+const MaxSelfPacedWindow = 45 * 24 * time.Hour
+
+// selfPacedGrace absorbs network latency on a self-paced player's deadline.
+const selfPacedGrace = time.Second
+
+// playerProgress is one self-paced player's position in the quiz. Each player
+// has their own clock: a question's timer starts when it is first shown to
+// them and is enforced when they answer or move on.
+type playerProgress struct {
+	Index           int       `json:"index"`
+	QuestionStarted time.Time `json:"question_started"` // zero until the question is shown
+	Answered        bool      `json:"answered"`
+	Finished        bool      `json:"finished"`
+	Streak          int       `json:"streak"` // correct answers in a row so far
+}
+
+// deadline returns when the player's current question closes.
+func (p playerProgress) deadline(q storedQuestion) time.Time {
+	return p.QuestionStarted.Add(time.Duration(q.TimeLimit) * time.Second)
+}
+
+// expired reports whether the player's current question has timed out at now.
+func (p playerProgress) expired(q storedQuestion, now time.Time) bool {
+	return !p.QuestionStarted.IsZero() && now.After(p.deadline(q).Add(selfPacedGrace))
+}
+
+// progressEntry is one row of the host's self-paced progress dashboard.
+type progressEntry struct {
+	PlayerID string `json:"player_id"`
+	Name     string `json:"name"`
+	Score    int    `json:"score"`
+	Answered int    `json:"answered"`
+	Finished bool   `json:"finished"`
+}
+
+// scheduleClose ends a self-paced session when its window closes.
+func (e *Engine) scheduleClose(ctx context.Context, sessionCode string, closesAt *time.Time) error {
+	if closesAt == nil {
+		return nil
+	}
+	return e.schedule(ctx, timer{Code: sessionCode, Kind: timerSelfPacedClose}, *closesAt)
+}
+
+// closeSelfPaced moves a self-paced session to the podium, if still open.
+func (e *Engine) closeSelfPaced(ctx context.Context, sessionCode string) error {
+	state, err := e.loadState(ctx, sessionCode)
+	if err != nil {
+		return err
+	}
+	if state.Phase != PhaseSelfPaced {
+		return nil
+	}
+	return e.triggerGameOver(ctx, sessionCode)
+}
+
+// selfPacedState loads state for a self-paced session whose window is open.
+func (e *Engine) selfPacedState(ctx context.Context, sessionCode string) (*GameState, error) {
+	state, err := e.loadState(ctx, sessionCode)
+	if err != nil {
+		return nil, err
+	}
+	if state.Phase != PhaseSelfPaced {
+		return nil, fmt.Errorf("not a self-paced session (current: %s)", state.Phase)
+	}
+	if closesAt := state.Settings.ClosesAt; closesAt != nil && time.Now().After(*closesAt) {
+		go func() {
+			if err := e.closeSelfPaced(context.Background(), sessionCode); err != nil {
+				log.Printf("engine: close self-paced session error: %v", err)
+			}
+		}()
+		return nil, fmt.Errorf("session closed at %s", closesAt.Format(time.RFC3339))
+	}
+	return state, nil
+}
+
+// SelfPacedQuestion returns the player's current question, starting its clock
+// the first time it is shown. Reconnecting does not restart the clock. A player
+// who has finished gets their final standing instead.
+func (e *Engine) SelfPacedQuestion(ctx context.Context, sessionCode, playerID string) (*hub.Message, error) {
+	var msg *hub.Message
+	err := retryOnConflict(func() (err error) {
+		msg, err = e.selfPacedQuestion(ctx, sessionCode, playerID)
+		return err
+	})
+	return msg, err
+}
+
+func (e *Engine) selfPacedQuestion(ctx context.Context, sessionCode, playerID string) (*hub.Message, error) {
+	state, err := e.selfPacedState(ctx, sessionCode)
+	if err != nil {
+		return nil, err
+	}
+	p, err := e.loadProgress(ctx, sessionCode, playerID)
+	if err != nil {
+		return nil, err
+	}
+	if p.Finished {
+		return e.selfPacedFinishedMessage(ctx, state)
+	}
+
+	questions, err := e.loadCachedQuestions(ctx, sessionCode)
+	if err != nil {
+		return nil, err
+	}
+	if p.QuestionStarted.IsZero() {
+		shown := p
+		shown.QuestionStarted = time.Now()
+		if err := e.swapProgress(ctx, state, playerID, p, shown); err != nil {
+			return nil, err
+		}
+		p = shown
+	}
+	q := playerView(questions[p.Index], state.Settings, state.SessionID, playerID)
+	msg := hub.Message{
+		Type:    hub.MsgQuestion,
+		Payload: buildSelfPacedQuestionPayload(q, p, state.TotalQuestions, time.Now()),
+	}
+	return &msg, nil
+}
+
+// AdvanceSelfPaced moves the player on to their next question once the
+// current one is answered or has timed out, and sends it to them.
+func (e *Engine) AdvanceSelfPaced(ctx context.Context, sessionCode, playerID string) error {
+	if err := retryOnConflict(func() error {
+		return e.advanceSelfPaced(ctx, sessionCode, playerID)
+	}); err != nil {
+		return err
+	}
+
+	msg, err := e.SelfPacedQuestion(ctx, sessionCode, playerID)
+	if err != nil {
+		return err
+	}
+	e.hub.BroadcastToPlayer(sessionCode, playerID, *msg)
+	return nil
+}
+
+// advanceSelfPaced moves the player past their current question. Only a
+// question the player has actually seen is left behind; otherwise the same
+// question is sent again.
+func (e *Engine) advanceSelfPaced(ctx context.Context, sessionCode, playerID string) error {
+	state, err := e.selfPacedState(ctx, sessionCode)
+	if err != nil {
+		return err
+	}
+	p, err := e.loadProgress(ctx, sessionCode, playerID)
+	if err != nil {
+		return err
+	}
+	if p.Finished || p.QuestionStarted.IsZero() {
+		return nil
+	}
+	questions, err := e.loadCachedQuestions(ctx, sessionCode)
+	if err != nil {
+		return err
+	}
+	q := questions[p.Index]
+	if !p.Answered && !p.expired(q, time.Now()) {
+		return fmt.Errorf("current question is still open")
+	}
+
+	// Move on only from the progress loaded, so a concurrent advance cannot
+	// skip the next question.
+	next := playerProgress{Index: p.Index + 1, Streak: p.Streak}
+	next.Finished = next.Index >= state.TotalQuestions
+	// Timed out without answering: like a missed live question, this breaks
+	// the streak.
+	missed := !p.Answered && q.Type != models.QuestionTypePoll
+	if missed {
+		next.Streak = 0
+	}
+	if err := e.swapProgress(ctx, state, playerID, p, next); err != nil {
+		return err
+	}
+
+	if missed {
+		_, _ = e.db.Exec(ctx, `UPDATE game_players SET streak = 0 WHERE id::text = $1`, playerID)
+	}
+	if next.Finished {
+		_, _ = e.db.Exec(ctx, `UPDATE game_players SET finished_at = NOW() WHERE id::text = $1`, playerID)
+	}
+	e.broadcastProgress(ctx, sessionCode, state)
+	return nil
+}
+
+// submitSelfPaced grades a self-paced answer immediately against the player's
+// own clock and reveals the result to that player only.
+func (e *Engine) submitSelfPaced(ctx context.Context, state *GameState, playerID string, sub Submission) error {
+	sessionCode := state.SessionCode
+	if _, err := e.selfPacedState(ctx, sessionCode); err != nil {
+		return err
+	}
+	p, err := e.loadProgress(ctx, sessionCode, playerID)
+	if err != nil {
+		return err
+	}
+	if p.Finished || p.QuestionStarted.IsZero() {
+		return fmt.Errorf("no open question for player")
+	}
+	if p.Answered {
+		return nil // already answered
+	}
+
+	questions, err := e.loadCachedQuestions(ctx, sessionCode)
+	if err != nil {
+		return err
+	}
+	q := questions[p.Index]
+	if q.ID != sub.QuestionID {
+		return fmt.Errorf("answer for wrong question")
+	}
+	now := time.Now()
+	if p.expired(q, now) {
+		return fmt.Errorf("time is up")
+	}
+	ans, err := validateSubmission(q, sub)
+	if err != nil {
+		return fmt.Errorf("invalid answer: %w", err)
+	}
+	ans.AnsweredAt = now
+
+	elapsed := now.Sub(p.QuestionStarted)
+	isCorrect, points := evaluateAnswer(q, ans, elapsed.Seconds())
+	if points < 0 && !state.Settings.NegativeScores {
+		var score int
+		if err := e.db.QueryRow(ctx, `SELECT score FROM game_players WHERE id::text = $1`, playerID).Scan(&score); err != nil {
+			return err
+		}
+		points = PenaltyPoints(points, score, false)
+	}
+	streak, bonus := p.Streak, 0
+	if q.Type != models.QuestionTypePoll {
+		if isCorrect {
+			streak++
+			bonus = StreakBonusFor(streak, state.Settings.StreakSchedule)
+			points += bonus
+		} else {
+			streak = 0
+		}
+	}
+
+	// Mark answered, with the new streak, before scoring, and only if the
+	// progress loaded is still current, so a duplicate submission cannot
+	// score twice or count towards the streak twice.
+	answered := p
+	answered.Answered = true
+	answered.Streak = streak
+	if err := e.swapProgress(ctx, state, playerID, p, answered); err != nil {
+		return err
+	}
+
+	entry := revealScoreEntry{
+		IsCorrect:   isCorrect,
+		Points:      points,
+		OptionID:    ans.OptionID,
+		Answer:      ans.Text,
+		Guess:       ans.Number,
+		Streak:      streak,
+		StreakBonus: bonus,
+	}
+	if err := e.recordAnswer(ctx, state, playerID, q, ans, elapsed.Seconds(), &entry); err != nil {
+		return err
+	}
+	_, _ = e.db.Exec(ctx,
+		`UPDATE game_players SET answered = answered + 1, answer_time_ms = answer_time_ms + $1 WHERE id::text = $2`,
+		elapsed.Milliseconds(), playerID,
+	)
+
+	e.hub.BroadcastToPlayer(sessionCode, playerID, hub.Message{
+		Type:    hub.MsgAnswerReveal,
+		Payload: buildRevealPayload(q, map[string]revealScoreEntry{playerID: entry}),
+	})
+	e.broadcastProgress(ctx, sessionCode, state)
+	return nil
+}
+
+// SelfPacedProgress returns the host's progress dashboard for a self-paced session.
+func (e *Engine) SelfPacedProgress(ctx context.Context, sessionCode string) (*hub.Message, error) {
+	state, err := e.loadState(ctx, sessionCode)
+	if err != nil {
+		return nil, err
+	}
+	if state.Phase != PhaseSelfPaced {
+		return nil, nil
+	}
+	return e.progressMessage(ctx, state)
+}
+
+func (e *Engine) broadcastProgress(ctx context.Context, sessionCode string, state *GameState) {
+	msg, err := e.progressMessage(ctx, state)
+	if err != nil {
+		log.Printf("engine: progress error: %v", err)
+		return
+	}
+	e.hub.BroadcastToHost(sessionCode, *msg)
+}
+
+func (e *Engine) progressMessage(ctx context.Context, state *GameState) (*hub.Message, error) {
+	rows, err := e.db.Query(ctx,
+		`SELECT id::text, name, score, answered, finished_at IS NOT NULL FROM game_players
+		 WHERE session_id = $1 ORDER BY score DESC, answer_time_ms ASC`,
+		state.SessionID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	players := []progressEntry{}
+	for rows.Next() {
+		var p progressEntry
+		if err := rows.Scan(&p.PlayerID, &p.Name, &p.Score, &p.Answered, &p.Finished); err != nil {
+			return nil, err
+		}
+		players = append(players, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	msg := hub.Message{
+		Type: hub.MsgProgress,
+		Payload: map[string]any{
+			"total_questions": state.TotalQuestions,
+			"closes_at":       state.Settings.ClosesAt,
+			"players":         players,
+		},
+	}
+	return &msg, nil
+}
+
+func (e *Engine) selfPacedFinishedMessage(ctx context.Context, state *GameState) (*hub.Message, error) {
+	payload, err := e.leaderboardPayload(ctx, state)
+	if err != nil {
+		return nil, err
+	}
+	payload["closes_at"] = state.Settings.ClosesAt
+	msg := hub.Message{Type: hub.MsgSelfPacedFinished, Payload: payload}
+	return &msg, nil
+}
+
+// buildSelfPacedQuestionPayload is the player question payload plus the
+// player's own clock.
+func buildSelfPacedQuestionPayload(q storedQuestion, p playerProgress, total int, now time.Time) map[string]any {
+	payload := buildQuestionPayload(q, p.Index, total)
+	deadline := p.deadline(q)
+	payload["deadline"] = deadline
+	payload["remaining_ms"] = max(deadline.Sub(now), 0).Milliseconds()
+	payload["answered"] = p.Answered
+	return payload
+}
+
+func (e *Engine) loadProgress(ctx context.Context, sessionCode, playerID string) (playerProgress, error) {
+	var p playerProgress
+	raw, err := e.redis.HGet(ctx, redisKeyProgress(sessionCode), playerID).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return p, nil // not started yet
+	}
+	if err != nil {
+		return p, err
+	}
+	err = json.Unmarshal(raw, &p)
+	return p, err
+}
+
+// swapProgressScript sets field ARGV[1] of hash KEYS[1] to ARGV[3] if it
+// still holds ARGV[2], or is unset when ARGV[2] is empty. The hash expires
+// after ARGV[4] milliseconds.
+var swapProgressScript = redis.NewScript(`
+if (redis.call('HGET', KEYS[1], ARGV[1]) or '') ~= ARGV[2] then
+	return 0
+end
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[3])
+redis.call('PEXPIRE', KEYS[1], ARGV[4])
+return 1
+`)
+
+// swapProgress saves the player's progress as to if it is still from, as
+// loaded by loadProgress, and returns errStateChanged if it has changed.
+func (e *Engine) swapProgress(ctx context.Context, state *GameState, playerID string, from, to playerProgress) error {
+	var old []byte
+	if from != (playerProgress{}) {
+		var err error
+		if old, err = json.Marshal(from); err != nil {
+			return err
+		}
+	}
+	data, err := json.Marshal(to)
+	if err != nil {
+		return err
+	}
+	swapped, err := swapProgressScript.Run(ctx, e.redis,
+		[]string{redisKeyProgress(state.SessionCode)},
+		playerID, old, data, state.Settings.keyTTL().Milliseconds(),
+	).Int()
+	if err != nil {
+		return err
+	}
+	if swapped == 0 {
+		return errStateChanged
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package game
+
+import (
+	"context"
+	"hash/fnv"
+	"math/rand/v2"
+	"sort"
+
+	"github.com/HassanA01/Iftarootv2/backend/internal/models"
+)
+
+// arrangeQuestions applies the session's draw and shuffle settings, returning
+// the questions in the order they will be asked. A drawn subset keeps quiz
+// order unless questions are also shuffled.
+func arrangeQuestions(questions []storedQuestion, settings GameSettings) []storedQuestion {
+	if n := settings.QuestionCount; n > 0 && n < len(questions) {
+		picked := rand.Perm(len(questions))[:n] //nolint:gosec // question draw, not security sensitive
+		if !settings.ShuffleQuestions {
+			sort.Ints(picked)
+		}
+		drawn := make([]storedQuestion, 0, n)
+		for _, i := range picked {
+			drawn = append(drawn, questions[i])
+		}
+		questions = drawn
+	} else if settings.ShuffleQuestions {
+		rand.Shuffle(len(questions), func(a, b int) { //nolint:gosec // display order, not security sensitive
+			questions[a], questions[b] = questions[b], questions[a]
+		})
+	}
+
+	if settings.ShuffleOptions == models.OptionShuffleSession {
+		for i := range questions {
+			if shufflableOptions(questions[i]) {
+				shuffleOptions(questions[i].Options, rand.Shuffle) //nolint:gosec // display order, not security sensitive
+			}
+		}
+	}
+	return questions
+}
+
+// playerView returns q with its options in the order this player sees them.
+// Per-player orders are derived from the session, player and question IDs, so
+// a player who reconnects sees the same order again.
+func playerView(q storedQuestion, settings GameSettings, sessionID, playerID string) storedQuestion {
+	if settings.ShuffleOptions != models.OptionShufflePlayer || !shufflableOptions(q) {
+		return q
+	}
+	h := fnv.New64a()
+	h.Write([]byte(sessionID + "/" + playerID + "/" + q.ID))
+	seed := h.Sum64()
+	r := rand.New(rand.NewPCG(seed, seed>>1|1)) //nolint:gosec // display order, not security sensitive
+
+	q.Options = append([]storedOption(nil), q.Options...)
+	shuffleOptions(q.Options, r.Shuffle)
+	return q
+}
+
+// shufflableOptions reports whether option order is free to change. Ordering
+// questions are excluded: their display order is already shuffled away from
+// the answer at StartGame.
+func shufflableOptions(q storedQuestion) bool {
+	return q.Type != models.QuestionTypeOrdering && len(q.Options) > 1
+}
+
+func shuffleOptions(opts []storedOption, shuffle func(n int, swap func(i, j int))) {
+	shuffle(len(opts), func(a, b int) { opts[a], opts[b] = opts[b], opts[a] })
+}
+
+// recordSessionQuestions stores the questions a session used, in order, with
+// the option order everyone was shown and the round each is in.
+func (e *Engine) recordSessionQuestions(ctx context.Context, sessionID string, questions []storedQuestion, rounds []RoundInfo) error {
+	for pos, q := range questions {
+		if _, err := e.db.Exec(ctx,
+			`INSERT INTO game_session_questions (session_id, position, question_id, option_order, round)
+			 VALUES ($1, $2, $3, $4::text[]::uuid[], $5)
+			 ON CONFLICT (session_id, position) DO UPDATE
+			 SET question_id = EXCLUDED.question_id, option_order = EXCLUDED.option_order, round = EXCLUDED.round`,
+			sessionID, pos, q.ID, q.optionIDs(), roundIndex(rounds, pos),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package game
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/HassanA01/Iftarootv2/backend/internal/models"
+)
+
+func shuffleTestQuestions(n int) []storedQuestion {
+	questions := make([]storedQuestion, n)
+	for i := range questions {
+		questions[i] = storedQuestion{ID: fmt.Sprintf("q%d", i), Type: models.QuestionTypeSingleChoice, Order: i}
+		for j := range 8 {
+			questions[i].Options = append(questions[i].Options, storedOption{ID: fmt.Sprintf("q%d-o%d", i, j)})
+		}
+	}
+	return questions
+}
+
+func TestArrangeQuestions(t *testing.T) {
+	t.Run("no settings keeps quiz order", func(t *testing.T) {
+		got := arrangeQuestions(shuffleTestQuestions(5), GameSettings{})
+		for i, q := range got {
+			if q.Order != i || q.Options[0].ID != fmt.Sprintf("q%d-o0", i) {
+				t.Fatalf("question %d changed: %+v", i, q)
+			}
+		}
+	})
+
+	t.Run("drawn subset keeps quiz order", func(t *testing.T) {
+		got := arrangeQuestions(shuffleTestQuestions(10), GameSettings{QuestionCount: 4})
+		if len(got) != 4 {
+			t.Fatalf("got %d questions, want 4", len(got))
+		}
+		if !slices.IsSortedFunc(got, func(a, b storedQuestion) int { return a.Order - b.Order }) {
+			t.Errorf("subset not in quiz order: %v", got)
+		}
+	})
+
+	t.Run("count above quiz size uses all", func(t *testing.T) {
+		if got := arrangeQuestions(shuffleTestQuestions(3), GameSettings{QuestionCount: 10}); len(got) != 3 {
+			t.Errorf("got %d questions, want 3", len(got))
+		}
+	})
+
+	t.Run("shuffle keeps every question", func(t *testing.T) {
+		got := arrangeQuestions(shuffleTestQuestions(6), GameSettings{ShuffleQuestions: true})
+		seen := map[string]bool{}
+		for _, q := range got {
+			seen[q.ID] = true
+		}
+		if len(seen) != 6 {
+			t.Errorf("questions lost or duplicated: %v", got)
+		}
+	})
+}
+
+func TestPlayerView(t *testing.T) {
+	q := shuffleTestQuestions(1)[0]
+	perPlayer := GameSettings{ShuffleOptions: models.OptionShufflePlayer}
+
+	if got := playerView(q, GameSettings{}, "s1", "p1"); !slices.Equal(got.optionIDs(), q.optionIDs()) {
+		t.Error("options reordered without per-player shuffle")
+	}
+
+	a := playerView(q, perPlayer, "s1", "p1")
+	if !slices.Equal(a.optionIDs(), playerView(q, perPlayer, "s1", "p1").optionIDs()) {
+		t.Error("per-player order should be stable for the same player")
+	}
+	if !slices.Equal(q.optionIDs(), shuffleTestQuestions(1)[0].optionIDs()) {
+		t.Error("playerView must not reorder the shared question's options")
+	}
+	differs := false
+	for i := range 10 {
+		if !slices.Equal(a.optionIDs(), playerView(q, perPlayer, "s1", fmt.Sprintf("other-%d", i)).optionIDs()) {
+			differs = true
+			break
+		}
+	}
+	if !differs {
+		t.Error("expected different players to see different orders")
+	}
+
+	ordering := q
+	ordering.Type = models.QuestionTypeOrdering
+	if got := playerView(ordering, perPlayer, "s1", "p1"); !slices.Equal(got.optionIDs(), ordering.optionIDs()) {
+		t.Error("ordering questions should not be reshuffled per player")
+	}
+}
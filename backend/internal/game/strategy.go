@@ -0,0 +1,113 @@
+package game
+
+import "math"
+
+// Built-in scoring strategy names, stored on quizzes.scoring_strategy and
+// optionally overridden per question.
+const (
+	ScoringLinear      = "linear"      // BasePoints decaying linearly to 0 over the time limit
+	ScoringFlat        = "flat"        // BasePoints regardless of speed
+	ScoringStepped     = "stepped"     // fixed tiers by how quickly the player answered
+	ScoringExponential = "exponential" // fast decay that rewards the quickest answers
+	ScoringNegative    = "negative"    // linear, but wrong answers lose WrongAnswerPenalty
+)
+
+// DefaultScoringStrategy is used when neither the quiz nor the question sets one.
+const DefaultScoringStrategy = ScoringLinear
+
+// WrongAnswerPenalty is deducted for a wrong answer under negative marking.
+const WrongAnswerPenalty = 250
+
+// ScoringStrategy turns a graded answer into points.
+type ScoringStrategy interface {
+	// Points returns the points for an answer earning credit in [0, 1],
+	// submitted elapsed seconds into a question lasting timeLimit seconds.
+	Points(credit, elapsed float64, timeLimit int) int
+}
+
+// ScoringFunc adapts a plain function to ScoringStrategy.
+type ScoringFunc func(credit, elapsed float64, timeLimit int) int
+
+// Points implements ScoringStrategy.
+func (f ScoringFunc) Points(credit, elapsed float64, timeLimit int) int {
+	return f(credit, elapsed, timeLimit)
+}
+
+// steppedTiers maps the fraction of the time limit used to the points awarded.
+var steppedTiers = []struct {
+	upTo   float64
+	points int
+}{
+	{0.25, BasePoints},
+	{0.50, 750},
+	{0.75, 500},
+	{1.00, 250},
+}
+
+var scoringStrategies = map[string]ScoringStrategy{
+	ScoringLinear: ScoringFunc(func(credit, elapsed float64, timeLimit int) int {
+		return CalculatePartialPoints(elapsed, timeLimit, credit)
+	}),
+	ScoringFlat: ScoringFunc(func(credit, _ float64, _ int) int {
+		return scaleByCredit(BasePoints, credit)
+	}),
+	ScoringStepped: ScoringFunc(func(credit, elapsed float64, timeLimit int) int {
+		ratio := 0.0
+		if timeLimit > 0 {
+			ratio = elapsed / float64(timeLimit)
+		}
+		for _, tier := range steppedTiers {
+			if ratio <= tier.upTo {
+				return scaleByCredit(tier.points, credit)
+			}
+		}
+		return MinPoints
+	}),
+	ScoringExponential: ScoringFunc(func(credit, elapsed float64, timeLimit int) int {
+		if timeLimit <= 0 {
+			return scaleByCredit(BasePoints, credit)
+		}
+		ratio := math.Max(0, elapsed/float64(timeLimit))
+		if ratio >= 1 {
+			return MinPoints
+		}
+		return scaleByCredit(int(math.Round(float64(BasePoints)*math.Exp(-3*ratio))), credit)
+	}),
+	ScoringNegative: ScoringFunc(func(credit, elapsed float64, timeLimit int) int {
+		if credit <= 0 {
+			return -WrongAnswerPenalty
+		}
+		return CalculatePartialPoints(elapsed, timeLimit, credit)
+	}),
+}
+
+// IsScoringStrategy reports whether name is a built-in strategy.
+func IsScoringStrategy(name string) bool {
+	_, ok := scoringStrategies[name]
+	return ok
+}
+
+// StrategyFor returns the named strategy, falling back to DefaultScoringStrategy
+// for unknown or empty names.
+func StrategyFor(name string) ScoringStrategy {
+	if s, ok := scoringStrategies[name]; ok {
+		return s
+	}
+	return scoringStrategies[DefaultScoringStrategy]
+}
+
+// PenaltyPoints caps the WrongAnswerPenalty lost by a player with score at
+// that score, unless NegativeScores allows going below zero.
+func PenaltyPoints(points, score int, negative bool) int {
+	if points >= 0 || negative {
+		return points
+	}
+	return max(points, -max(score, 0))
+}
+
+func scaleByCredit(points int, credit float64) int {
+	if credit <= 0 {
+		return MinPoints
+	}
+	return int(math.Round(float64(points) * math.Min(credit, 1)))
+}
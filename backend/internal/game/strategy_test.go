@@ -0,0 +1,90 @@
+package game
+
+import "testing"
+
+func TestStrategyFor(t *testing.T) {
+	for _, name := range []string{ScoringLinear, ScoringFlat, ScoringStepped, ScoringExponential, ScoringNegative} {
+		if !IsScoringStrategy(name) {
+			t.Errorf("%s should be a built-in strategy", name)
+		}
+	}
+	if IsScoringStrategy("bogus") {
+		t.Error("bogus should not be a strategy")
+	}
+	if got := StrategyFor("").Points(1, 10, 20); got != CalculatePoints(10, 20) {
+		t.Errorf("empty name should fall back to linear, got %d", got)
+	}
+}
+
+func TestScoringStrategies(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy string
+		credit   float64
+		elapsed  float64
+		want     int
+	}{
+		{"linear half time", ScoringLinear, 1, 10, 500},
+		{"linear wrong", ScoringLinear, 0, 1, 0},
+		{"flat ignores speed", ScoringFlat, 1, 19, BasePoints},
+		{"flat half credit", ScoringFlat, 0.5, 19, BasePoints / 2},
+		{"stepped first tier", ScoringStepped, 1, 4, BasePoints},
+		{"stepped second tier", ScoringStepped, 1, 8, 750},
+		{"stepped last tier", ScoringStepped, 1, 19, 250},
+		{"stepped over time", ScoringStepped, 1, 25, 0},
+		{"exponential instant", ScoringExponential, 1, 0, BasePoints},
+		{"exponential half time", ScoringExponential, 1, 10, 223},
+		{"negative correct", ScoringNegative, 1, 10, 500},
+		{"negative wrong", ScoringNegative, 0, 10, -WrongAnswerPenalty},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := StrategyFor(tc.strategy).Points(tc.credit, tc.elapsed, 20)
+			if got != tc.want {
+				t.Errorf("%s.Points(%v, %v, 20) = %d, want %d", tc.strategy, tc.credit, tc.elapsed, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateAnswerUsesQuestionStrategy(t *testing.T) {
+	q := storedQuestion{
+		TimeLimit:       20,
+		ScoringStrategy: ScoringNegative,
+		Options:         []storedOption{{ID: "o1", IsCorrect: true}, {ID: "o2"}},
+	}
+	if correct, points := evaluateAnswer(q, playerAnswer{OptionID: "o2"}, 5); correct || points != -WrongAnswerPenalty {
+		t.Errorf("negative marking: got correct=%v points=%d", correct, points)
+	}
+
+	questions := []storedQuestion{{ScoringStrategy: ScoringFlat}, {}}
+	resolveScoringStrategies(questions, ScoringStepped)
+	if questions[0].ScoringStrategy != ScoringFlat || questions[1].ScoringStrategy != ScoringStepped {
+		t.Errorf("unexpected resolution: %q, %q", questions[0].ScoringStrategy, questions[1].ScoringStrategy)
+	}
+}
+
+func TestPenaltyPoints(t *testing.T) {
+	penalty := -WrongAnswerPenalty * 2 // a 2x question
+	tests := []struct {
+		name     string
+		points   int
+		score    int
+		negative bool
+		want     int
+	}{
+		{"points are untouched", 800, 0, false, 800},
+		{"penalty covered by score", penalty, 2000, false, penalty},
+		{"penalty floored at zero", penalty, 300, false, -300},
+		{"no points to lose", penalty, 0, false, 0},
+		{"already negative stays put when floored", penalty, -100, false, 0},
+		{"negative scores allowed", penalty, 0, true, penalty},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := PenaltyPoints(tc.points, tc.score, tc.negative); got != tc.want {
+				t.Errorf("PenaltyPoints = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
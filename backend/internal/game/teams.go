@@ -0,0 +1,77 @@
+package game
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"github.com/google/uuid"
+
+	"github.com/HassanA01/Iftarootv2/backend/internal/models"
+)
+
+// leaderboardPayload is the payload shared by leaderboard and podium
+// messages: player entries, plus team standings when the session has teams.
+func (e *Engine) leaderboardPayload(ctx context.Context, state *GameState) (map[string]any, error) {
+	entries, err := e.getLeaderboard(ctx, state.SessionID, state.Settings.TieBreaker)
+	if err != nil {
+		return nil, err
+	}
+	payload := state.addRound(map[string]any{"entries": entries}, state.CurrentIndex)
+	if state.Settings.TeamScoring != "" {
+		payload["teams"] = aggregateTeams(entries, state.Settings.TeamScoring)
+		payload["team_scoring"] = state.Settings.TeamScoring
+	}
+	return payload, nil
+}
+
+// aggregateTeams combines player entries into ranked team entries. Teams
+// level on score share a rank, and the next rank skips accordingly. Players
+// without a team are left out.
+func aggregateTeams(entries []models.LeaderboardEntry, scoring models.TeamScoring) []models.TeamLeaderboardEntry {
+	byTeam := make(map[uuid.UUID]*models.TeamLeaderboardEntry)
+	var order []uuid.UUID
+	for _, p := range entries {
+		if p.TeamID == nil {
+			continue
+		}
+		t, ok := byTeam[*p.TeamID]
+		if !ok {
+			t = &models.TeamLeaderboardEntry{TeamID: *p.TeamID, Name: p.TeamName}
+			if scoring == models.TeamScoringBest {
+				t.Score = math.MinInt
+			}
+			byTeam[*p.TeamID] = t
+			order = append(order, *p.TeamID)
+		}
+		t.Players++
+		switch scoring {
+		case models.TeamScoringBest:
+			t.Score = max(t.Score, p.Score)
+		default: // sum, and the running total for average
+			t.Score += p.Score
+		}
+	}
+
+	teams := make([]models.TeamLeaderboardEntry, 0, len(order))
+	for _, id := range order {
+		t := byTeam[id]
+		if scoring == models.TeamScoringAverage {
+			t.Score = int(math.Round(float64(t.Score) / float64(t.Players)))
+		}
+		teams = append(teams, *t)
+	}
+	sort.SliceStable(teams, func(i, j int) bool {
+		if teams[i].Score != teams[j].Score {
+			return teams[i].Score > teams[j].Score
+		}
+		return teams[i].Name < teams[j].Name
+	})
+	for i := range teams {
+		teams[i].Rank = i + 1
+		if i > 0 && teams[i].Score == teams[i-1].Score {
+			teams[i].Rank = teams[i-1].Rank
+		}
+	}
+	return teams
+}
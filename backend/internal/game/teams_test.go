@@ -0,0 +1,72 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/HassanA01/Iftarootv2/backend/internal/models"
+)
+
+func TestAggregateTeams(t *testing.T) {
+	red, blue := uuid.New(), uuid.New()
+	entries := []models.LeaderboardEntry{
+		{Name: "a", Score: 900, TeamID: &red, TeamName: "Red"},
+		{Name: "b", Score: 700, TeamID: &blue, TeamName: "Blue"},
+		{Name: "c", Score: 600, TeamID: &blue, TeamName: "Blue"},
+		{Name: "d", Score: 500},
+		{Name: "e", Score: 100, TeamID: &red, TeamName: "Red"},
+	}
+
+	tests := []struct {
+		scoring   models.TeamScoring
+		wantFirst string
+		wantRed   int
+		wantBlue  int
+	}{
+		{models.TeamScoringSum, "Blue", 1000, 1300},
+		{models.TeamScoringAverage, "Blue", 500, 650},
+		{models.TeamScoringBest, "Red", 900, 700},
+	}
+	for _, tc := range tests {
+		t.Run(string(tc.scoring), func(t *testing.T) {
+			teams := aggregateTeams(entries, tc.scoring)
+			if len(teams) != 2 {
+				t.Fatalf("got %d teams, want 2 (teamless players excluded)", len(teams))
+			}
+			if teams[0].Name != tc.wantFirst || teams[0].Rank != 1 || teams[1].Rank != 2 {
+				t.Errorf("ranking = %+v", teams)
+			}
+			for _, team := range teams {
+				want := tc.wantBlue
+				if team.TeamID == red {
+					want = tc.wantRed
+				}
+				if team.Score != want {
+					t.Errorf("%s score = %d, want %d", team.Name, team.Score, want)
+				}
+				if team.Players != 2 {
+					t.Errorf("%s players = %d, want 2", team.Name, team.Players)
+				}
+			}
+		})
+	}
+}
+
+func TestAggregateTeamsSharedRanks(t *testing.T) {
+	red, blue, green := uuid.New(), uuid.New(), uuid.New()
+	entries := []models.LeaderboardEntry{
+		{Name: "a", Score: 800, TeamID: &red, TeamName: "Red"},
+		{Name: "b", Score: 800, TeamID: &blue, TeamName: "Blue"},
+		{Name: "c", Score: 300, TeamID: &green, TeamName: "Green"},
+	}
+
+	teams := aggregateTeams(entries, models.TeamScoringSum)
+	var got []int
+	for _, team := range teams {
+		got = append(got, team.Rank)
+	}
+	if len(got) != 3 || got[0] != 1 || got[1] != 1 || got[2] != 3 {
+		t.Errorf("ranks = %v, want [1 1 3]", got)
+	}
+}
@@ -0,0 +1,93 @@
+package game
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxTypedAnswerLength caps how many runes of a typed answer are kept.
+const MaxTypedAnswerLength = 200
+
+// TextMatchRules control how a typed answer is compared to accepted answers.
+type TextMatchRules struct {
+	CaseSensitive    bool
+	IgnoreDiacritics bool
+	MaxDistance      int // Levenshtein edits tolerated after normalisation
+}
+
+// arabicFolds maps Arabic letter variants that players commonly type
+// interchangeably onto a single form.
+var arabicFolds = strings.NewReplacer(
+	"أ", "ا", "إ", "ا", "آ", "ا", "ٱ", "ا",
+	"ى", "ي", "ة", "ه", "ؤ", "و", "ئ", "ي",
+	"ـ", "", // tatweel
+)
+
+// NormalizeAnswer canonicalises s for comparison: NFKC, trimmed, with runs of
+// whitespace collapsed. Case is folded unless rules.CaseSensitive. With
+// rules.IgnoreDiacritics, combining marks (Latin accents, Arabic harakat) are
+// removed and Arabic letter variants are folded.
+func NormalizeAnswer(s string, rules TextMatchRules) string {
+	s = norm.NFKC.String(s)
+	if rules.IgnoreDiacritics {
+		t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+		if out, _, err := transform.String(t, s); err == nil {
+			s = out
+		}
+		s = arabicFolds.Replace(s)
+	}
+	if !rules.CaseSensitive {
+		s = strings.ToLower(s)
+	}
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// MatchTypedAnswer reports whether input matches any accepted answer under rules.
+func MatchTypedAnswer(input string, accepted []string, rules TextMatchRules) bool {
+	got := NormalizeAnswer(input, rules)
+	if got == "" {
+		return false
+	}
+	for _, a := range accepted {
+		want := NormalizeAnswer(a, rules)
+		if want == "" {
+			continue
+		}
+		if got == want || Levenshtein(got, want) <= rules.MaxDistance {
+			return true
+		}
+	}
+	return false
+}
+
+// Levenshtein returns the edit distance between a and b, counted in runes.
+func Levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 {
+		return len(rb)
+	}
+	if len(rb) == 0 {
+		return len(ra)
+	}
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,73 @@
+package game
+
+import "testing"
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"kitten", "sitting", 3},
+		{"makkah", "makka", 1},
+		{"مكة", "مكه", 1},
+	}
+	for _, tc := range tests {
+		if got := Levenshtein(tc.a, tc.b); got != tc.want {
+			t.Errorf("Levenshtein(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestNormalizeAnswer(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		rules TextMatchRules
+		want  string
+	}{
+		{"trims and collapses whitespace", "  Abu   Bakr ", TextMatchRules{}, "abu bakr"},
+		{"case sensitive keeps case", "Abu Bakr", TextMatchRules{CaseSensitive: true}, "Abu Bakr"},
+		{"nfkc folds full-width", "ＡＢＣ", TextMatchRules{}, "abc"},
+		{"diacritics kept by default", "Café", TextMatchRules{}, "café"},
+		{"latin diacritics removed", "Café", TextMatchRules{IgnoreDiacritics: true}, "cafe"},
+		{"arabic harakat removed", "مُحَمَّد", TextMatchRules{IgnoreDiacritics: true}, "محمد"},
+		{"arabic alef variants folded", "أحمد", TextMatchRules{IgnoreDiacritics: true}, "احمد"},
+		{"arabic ta marbuta folded", "مكة", TextMatchRules{IgnoreDiacritics: true}, "مكه"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := NormalizeAnswer(tc.in, tc.rules); got != tc.want {
+				t.Errorf("NormalizeAnswer(%q) = %q, want %q", tc.in, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMatchTypedAnswer(t *testing.T) {
+	accepted := []string{"Makkah", "Mecca", "مكة"}
+	tests := []struct {
+		name  string
+		input string
+		rules TextMatchRules
+		want  bool
+	}{
+		{"exact", "Makkah", TextMatchRules{}, true},
+		{"case-insensitive", "  mecca ", TextMatchRules{}, true},
+		{"case-sensitive rejects", "mecca", TextMatchRules{CaseSensitive: true}, false},
+		{"typo rejected without tolerance", "Makah", TextMatchRules{}, false},
+		{"typo accepted with tolerance", "Makah", TextMatchRules{MaxDistance: 1}, true},
+		{"arabic variant needs folding", "مكه", TextMatchRules{}, false},
+		{"arabic variant with folding", "مكه", TextMatchRules{IgnoreDiacritics: true}, true},
+		{"empty never matches", "   ", TextMatchRules{MaxDistance: 5}, false},
+		{"unrelated", "Madinah", TextMatchRules{MaxDistance: 1}, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := MatchTypedAnswer(tc.input, accepted, tc.rules); got != tc.want {
+				t.Errorf("MatchTypedAnswer(%q) = %v, want %v", tc.input, got, tc.want)
+			}
+		})
+	}
+}
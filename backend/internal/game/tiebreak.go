@@ -0,0 +1,496 @@
+package game
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/HassanA01/Iftarootv2/backend/internal/hub"
+	"github.com/HassanA01/Iftarootv2/backend/internal/models"
+)
+
+// PodiumPlaces is how many top ranks sudden death settles.
+const PodiumPlaces = 3
+
+// SuddenDeathResultSeconds is how long a sudden-death result stays on screen
+// before the podium, or the next sudden-death question.
+const SuddenDeathResultSeconds = 3
+
+// redisKeyReserve returns the Redis key for a session's reserve questions.
+func redisKeyReserve(code string) string { return fmt.Sprintf("game:%s:reserve", code) }
+
+// redisKeySuddenDeath returns the Redis key for answers to the nth sudden-death
+// question of a session, counting from 1.
+func redisKeySuddenDeath(code string, n int) string {
+	return fmt.Sprintf("game:%s:sd%d:answers", code, n)
+}
+
+// leaderboardRow is a leaderboard entry with the keys ties are broken on.
+type leaderboardRow struct {
+	entry        models.LeaderboardEntry
+	tiebreak     int   // sudden-death placing among players level on points
+	answerMs     int64 // total time over the questions asked so far
+	firstCorrect *time.Time
+}
+
+// timedAnswer is a recorded answer with the time limit of its question.
+type timedAnswer struct {
+	playerID   string
+	questionID string
+	answerMs   *int64
+	answeredAt time.Time
+	correct    bool
+	limitMs    int64
+}
+
+// addAnswerTimes sets each row's total answer time and earliest correct
+// answer from a session's answers. A question someone answered counts at its
+// full time limit for every player who missed it, so skipping questions never
+// pays off.
+func addAnswerTimes(rows []leaderboardRow, answers []timedAnswer) {
+	limits := make(map[string]int64)
+	taken := make(map[string]map[string]int64)
+	first := make(map[string]time.Time)
+	for _, a := range answers {
+		limits[a.questionID] = a.limitMs
+		if a.answerMs != nil {
+			if taken[a.playerID] == nil {
+				taken[a.playerID] = make(map[string]int64)
+			}
+			taken[a.playerID][a.questionID] = *a.answerMs
+		}
+		if t, ok := first[a.playerID]; a.correct && (!ok || a.answeredAt.Before(t)) {
+			first[a.playerID] = a.answeredAt
+		}
+	}
+	for i := range rows {
+		id := rows[i].entry.PlayerID.String()
+		rows[i].answerMs = 0
+		for q, limit := range limits {
+			if ms, ok := taken[id][q]; ok {
+				rows[i].answerMs += ms
+			} else {
+				rows[i].answerMs += limit
+			}
+		}
+		if t, ok := first[id]; ok {
+			rows[i].firstCorrect = &t
+		}
+	}
+}
+
+// sortLeaderboard orders rows by score, then sudden-death placing, then
+// total answer time and the earliest correct answer. The sort is stable, so
+// rows otherwise level keep their order.
+func sortLeaderboard(rows []leaderboardRow) {
+	slices.SortStableFunc(rows, func(a, b leaderboardRow) int {
+		if c := cmp.Compare(b.entry.Score, a.entry.Score); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(b.tiebreak, a.tiebreak); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.answerMs, b.answerMs); c != 0 {
+			return c
+		}
+		return compareNilLast(a.firstCorrect, b.firstCorrect, time.Time.Compare)
+	})
+}
+
+// compareNilLast compares two optional values with compare, ordering nil
+// after any value.
+func compareNilLast[T any](a, b *T, compare func(T, T) int) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+	return compare(*a, *b)
+}
+
+// levelWith reports whether r and o share a rank under tb.
+func (r leaderboardRow) levelWith(o leaderboardRow, tb models.TieBreaker) bool {
+	if r.entry.Score != o.entry.Score || r.tiebreak != o.tiebreak {
+		return false
+	}
+	if tb == models.TieBreakerShared || tb == models.TieBreakerSuddenDeath {
+		return true
+	}
+	return r.answerMs == o.answerMs &&
+		compareNilLast(r.firstCorrect, o.firstCorrect, time.Time.Compare) == 0
+}
+
+// rankLeaderboard ranks rows already in leaderboard order. Players level with
+// the one above them share their rank, and the next rank skips accordingly.
+func rankLeaderboard(rows []leaderboardRow, tb models.TieBreaker) []models.LeaderboardEntry {
+	entries := make([]models.LeaderboardEntry, 0, len(rows))
+	for i, r := range rows {
+		r.entry.Rank = i + 1
+		if i > 0 && r.levelWith(rows[i-1], tb) {
+			r.entry.Rank = entries[i-1].Rank
+		}
+		entries = append(entries, r.entry)
+	}
+	return entries
+}
+
+// podiumTie returns the players sharing the best podium rank that is shared
+// by more than one player, or nil if the podium is settled.
+func podiumTie(entries []models.LeaderboardEntry) []string {
+	var tied []string
+	for i, e := range entries {
+		if e.Rank > PodiumPlaces {
+			break
+		}
+		if i > 0 && e.Rank == entries[i-1].Rank {
+			if len(tied) == 0 {
+				tied = append(tied, entries[i-1].PlayerID.String())
+			}
+			tied = append(tied, e.PlayerID.String())
+			continue
+		}
+		if len(tied) > 0 {
+			break
+		}
+	}
+	return tied
+}
+
+// suddenDeathPlacings orders the players who answered q correctly, fastest
+// first.
+func suddenDeathPlacings(q storedQuestion, answers map[string]playerAnswer) []string {
+	var placed []string
+	for playerID, ans := range answers {
+		if correct, _ := gradeAnswer(q, ans); correct {
+			placed = append(placed, playerID)
+		}
+	}
+	sort.Slice(placed, func(i, j int) bool {
+		a, b := answers[placed[i]].AnsweredAt, answers[placed[j]].AnsweredAt
+		if !a.Equal(b) {
+			return a.Before(b)
+		}
+		return placed[i] < placed[j]
+	})
+	return placed
+}
+
+// settleTie returns new tiebreak values for every player level on points with
+// the tied group, given their current values. The tied group is split into
+// its placings, in order, followed by everyone else in it; players above and
+// below the group keep their order.
+func settleTie(tiebreaks map[string]int, tied, placings []string) map[string]int {
+	groupValue := tiebreaks[tied[0]]
+	values := make([]int, 0, len(tiebreaks))
+	for _, v := range tiebreaks {
+		if !slices.Contains(values, v) {
+			values = append(values, v)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(values)))
+
+	// Each level becomes a group of players; the tied level is replaced by one
+	// group per placing and a final group for the rest.
+	var groups [][]string
+	for _, v := range values {
+		if v != groupValue {
+			var group []string
+			for id, tv := range tiebreaks {
+				if tv == v {
+					group = append(group, id)
+				}
+			}
+			groups = append(groups, group)
+			continue
+		}
+		for _, id := range placings {
+			groups = append(groups, []string{id})
+		}
+		var rest []string
+		for _, id := range tied {
+			if !slices.Contains(placings, id) {
+				rest = append(rest, id)
+			}
+		}
+		if len(rest) > 0 {
+			groups = append(groups, rest)
+		}
+	}
+
+	settled := make(map[string]int, len(tiebreaks))
+	for i, group := range groups {
+		for _, id := range group {
+			settled[id] = len(groups) - i
+		}
+	}
+	return settled
+}
+
+// finishGame ends the game on the podium. Under the sudden_death tie-breaker a
+// tie on the podium is first played off with the next reserve question, for as
+// long as reserve questions last.
+func (e *Engine) finishGame(ctx context.Context, sessionCode string, state *GameState) error {
+	if state.Settings.TieBreaker != models.TieBreakerSuddenDeath || state.Settings.Mode == models.SessionModeSelfPaced {
+		return e.triggerGameOver(ctx, sessionCode)
+	}
+	entries, err := e.getLeaderboard(ctx, state.SessionID, state.Settings.TieBreaker)
+	if err != nil {
+		return err
+	}
+	tied := podiumTie(entries)
+	if len(tied) == 0 {
+		return e.triggerGameOver(ctx, sessionCode)
+	}
+	reserve, err := e.loadCachedReserve(ctx, sessionCode)
+	if err != nil || state.SuddenDeathRound >= len(reserve) {
+		return e.triggerGameOver(ctx, sessionCode)
+	}
+	return e.openSuddenDeath(ctx, state, reserve[state.SuddenDeathRound], tied)
+}
+
+// openSuddenDeath asks q to the tied players. Everyone else watches.
+func (e *Engine) openSuddenDeath(ctx context.Context, state *GameState, q storedQuestion, tied []string) error {
+	sessionCode := state.SessionCode
+	now := time.Now()
+	state.SuddenDeathRound++
+	state.SuddenDeathPlayers = tied
+	state.Phase = PhaseSuddenDeath
+	state.QuestionStarted = now
+	state.QuestionDeadline = now.Add(time.Duration(q.TimeLimit) * time.Second)
+	state.QuestionRun++
+	if err := e.saveState(ctx, sessionCode, state); err != nil {
+		return err
+	}
+	e.redis.Del(ctx, redisKeySuddenDeath(sessionCode, state.SuddenDeathRound))
+
+	e.hub.BroadcastToPlayers(sessionCode, hub.Message{
+		Type:    hub.MsgSuddenDeath,
+		Payload: buildSuddenDeathPayload(state, buildQuestionPayload(q, state.CurrentIndex, state.TotalQuestions), now),
+	})
+	e.hub.BroadcastToHost(sessionCode, hub.Message{
+		Type:    hub.MsgSuddenDeath,
+		Payload: buildSuddenDeathPayload(state, BuildHostQuestionPayload(q, state.CurrentIndex, state.TotalQuestions), now),
+	})
+
+	return e.schedule(ctx, timer{Code: sessionCode, Kind: timerSuddenDeath, Run: state.QuestionRun}, state.QuestionDeadline)
+}
+
+// submitSuddenDeathAnswer records a tied player's answer to the sudden-death
+// question. The first answer counts; once every tied player has answered the
+// question is resolved.
+func (e *Engine) submitSuddenDeathAnswer(ctx context.Context, state *GameState, playerID string, sub Submission) error {
+	if !slices.Contains(state.SuddenDeathPlayers, playerID) {
+		return fmt.Errorf("player is not in sudden death")
+	}
+	q, err := e.suddenDeathQuestion(ctx, state)
+	if err != nil {
+		return err
+	}
+	if q.ID != sub.QuestionID {
+		return fmt.Errorf("question_id mismatch")
+	}
+	ans, err := validateSubmission(q, sub)
+	if err != nil {
+		return fmt.Errorf("invalid answer: %w", err)
+	}
+	ans.AnsweredAt = time.Now()
+	data, err := json.Marshal(ans)
+	if err != nil {
+		return err
+	}
+
+	key := redisKeySuddenDeath(state.SessionCode, state.SuddenDeathRound)
+	placed, err := e.setOnce(ctx, state, key, playerID, string(data))
+	if err != nil {
+		return err
+	}
+	if !placed {
+		return nil // already answered
+	}
+
+	if answered, _ := e.redis.HLen(ctx, key).Result(); int(answered) >= len(state.SuddenDeathPlayers) {
+		return e.resolveSuddenDeath(ctx, state.SessionCode, state.QuestionRun)
+	}
+	return nil
+}
+
+// resolveSuddenDeath places the tied players who answered question run
+// correctly, fastest first, above the rest of the tie, and shows the result.
+// The game then finishes, which may call another sudden death if players are
+// still level on the podium. It does nothing once run is resolved.
+func (e *Engine) resolveSuddenDeath(ctx context.Context, sessionCode string, run int) error {
+	state, err := e.loadState(ctx, sessionCode)
+	if err != nil {
+		return err
+	}
+	if state.Phase != PhaseSuddenDeath || state.QuestionRun != run || len(state.SuddenDeathPlayers) == 0 {
+		return nil
+	}
+	tied := state.SuddenDeathPlayers
+	state.SuddenDeathPlayers = nil
+	if err := e.saveState(ctx, sessionCode, state); err != nil {
+		return err
+	}
+
+	q, err := e.suddenDeathQuestion(ctx, state)
+	if err != nil {
+		return err
+	}
+	raw, _ := e.redis.HGetAll(ctx, redisKeySuddenDeath(sessionCode, state.SuddenDeathRound)).Result()
+	answers := make(map[string]playerAnswer, len(raw))
+	scores := make(map[string]revealScoreEntry, len(raw))
+	for playerID, data := range raw {
+		var ans playerAnswer
+		if err := json.Unmarshal([]byte(data), &ans); err != nil {
+			continue
+		}
+		answers[playerID] = ans
+		correct, _ := gradeAnswer(q, ans)
+		scores[playerID] = revealScoreEntry{IsCorrect: correct, OptionID: ans.OptionID, Answer: ans.Text, Guess: ans.Number}
+	}
+	placings := suddenDeathPlacings(q, answers)
+	if len(placings) > 0 {
+		if err := e.saveTiebreaks(ctx, state.SessionID, tied, placings); err != nil {
+			return err
+		}
+	}
+
+	payload := buildRevealPayload(q, scores)
+	payload["sudden_death_round"] = state.SuddenDeathRound
+	payload["players"] = tied
+	payload["placings"] = placings
+	e.hub.Broadcast(sessionCode, hub.Message{
+		Type:    hub.MsgSuddenDeathResult,
+		Payload: payload,
+	})
+
+	return e.schedule(ctx, timer{Code: sessionCode, Kind: timerSuddenDeathResult, Run: run}, time.Now().Add(SuddenDeathResultSeconds*time.Second))
+}
+
+// closeSuddenDeathResult finishes the game once the result of sudden-death
+// question run has been shown, unless the game has moved on.
+func (e *Engine) closeSuddenDeathResult(ctx context.Context, sessionCode string, run int) error {
+	st, err := e.loadState(ctx, sessionCode)
+	if err != nil || st.Phase != PhaseSuddenDeath || st.QuestionRun != run {
+		return nil
+	}
+	return e.finishGame(ctx, sessionCode, st)
+}
+
+// saveTiebreaks stores the sudden-death result for the tied players and
+// everyone level on points with them.
+func (e *Engine) saveTiebreaks(ctx context.Context, sessionID string, tied, placings []string) error {
+	rows, err := e.db.Query(ctx,
+		`SELECT id::text, tiebreak FROM game_players
+		 WHERE session_id = $1 AND score = (SELECT score FROM game_players WHERE id::text = $2)`,
+		sessionID, tied[0],
+	)
+	if err != nil {
+		return err
+	}
+	tiebreaks := make(map[string]int)
+	for rows.Next() {
+		var id string
+		var tb int
+		if err := rows.Scan(&id, &tb); err != nil {
+			rows.Close()
+			return err
+		}
+		tiebreaks[id] = tb
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	settled := settleTie(tiebreaks, tied, placings)
+	ids := make([]string, 0, len(settled))
+	values := make([]int, 0, len(settled))
+	for id, v := range settled {
+		ids = append(ids, id)
+		values = append(values, v)
+	}
+	_, err = e.db.Exec(ctx,
+		`UPDATE game_players p SET tiebreak = u.tiebreak
+		 FROM unnest($1::text[], $2::int[]) AS u(id, tiebreak)
+		 WHERE p.id::text = u.id AND p.session_id = $3`,
+		ids, values, sessionID,
+	)
+	return err
+}
+
+// SuddenDeathMessage returns the open sudden-death question for a client
+// joining during it, or nil if none is open.
+func (e *Engine) SuddenDeathMessage(ctx context.Context, sessionCode string, isHost bool) (*hub.Message, error) {
+	state, err := e.loadState(ctx, sessionCode)
+	if err != nil {
+		return nil, err
+	}
+	if state.Phase != PhaseSuddenDeath || len(state.SuddenDeathPlayers) == 0 {
+		return nil, nil
+	}
+	q, err := e.suddenDeathQuestion(ctx, state)
+	if err != nil {
+		return nil, err
+	}
+	question := buildQuestionPayload(q, state.CurrentIndex, state.TotalQuestions)
+	if isHost {
+		question = BuildHostQuestionPayload(q, state.CurrentIndex, state.TotalQuestions)
+	}
+	return &hub.Message{Type: hub.MsgSuddenDeath, Payload: buildSuddenDeathPayload(state, question, time.Now())}, nil
+}
+
+// suddenDeathQuestion returns the reserve question being played.
+func (e *Engine) suddenDeathQuestion(ctx context.Context, state *GameState) (storedQuestion, error) {
+	reserve, err := e.loadCachedReserve(ctx, state.SessionCode)
+	if err != nil {
+		return storedQuestion{}, err
+	}
+	if state.SuddenDeathRound < 1 || state.SuddenDeathRound > len(reserve) {
+		return storedQuestion{}, fmt.Errorf("no sudden-death question %d", state.SuddenDeathRound)
+	}
+	return reserve[state.SuddenDeathRound-1], nil
+}
+
+// cacheReserveQuestions stores the quiz's reserve questions for sudden death.
+func (e *Engine) cacheReserveQuestions(ctx context.Context, sessionCode, quizID string, settings GameSettings) error {
+	reserve, err := e.loadReserveQuestions(ctx, quizID)
+	if err != nil {
+		return err
+	}
+	shuffleOrderingOptions(reserve)
+	data, err := json.Marshal(reserve)
+	if err != nil {
+		return err
+	}
+	return e.redis.Set(ctx, redisKeyReserve(sessionCode), data, settings.keyTTL()).Err()
+}
+
+func (e *Engine) loadCachedReserve(ctx context.Context, sessionCode string) ([]storedQuestion, error) {
+	data, err := e.redis.Get(ctx, redisKeyReserve(sessionCode)).Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("reserve questions not in cache: %w", err)
+	}
+	var reserve []storedQuestion
+	if err := json.Unmarshal(data, &reserve); err != nil {
+		return nil, err
+	}
+	return reserve, nil
+}
+
+// buildSuddenDeathPayload wraps a question payload with who is playing it
+// and how long is left at now.
+func buildSuddenDeathPayload(state *GameState, question map[string]any, now time.Time) map[string]any {
+	question["sudden_death_round"] = state.SuddenDeathRound
+	question["players"] = state.SuddenDeathPlayers
+	question["deadline"] = state.QuestionDeadline
+	question["remaining_ms"] = max(state.QuestionDeadline.Sub(now), 0).Milliseconds()
+	return question
+}
@@ -0,0 +1,157 @@
+package game
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/HassanA01/Iftarootv2/backend/internal/models"
+)
+
+func ranks(entries []models.LeaderboardEntry) []int {
+	out := make([]int, len(entries))
+	for i, e := range entries {
+		out[i] = e.Rank
+	}
+	return out
+}
+
+func TestRankLeaderboard(t *testing.T) {
+	early := time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC)
+	late := early.Add(time.Second)
+	rows := []leaderboardRow{
+		{entry: models.LeaderboardEntry{Name: "A", Score: 900}, answerMs: 4000, firstCorrect: &early},
+		{entry: models.LeaderboardEntry{Name: "B", Score: 900}, answerMs: 4000, firstCorrect: &late},
+		{entry: models.LeaderboardEntry{Name: "C", Score: 900}, answerMs: 4000, firstCorrect: &late},
+		{entry: models.LeaderboardEntry{Name: "D", Score: 500}},
+	}
+
+	if got := ranks(rankLeaderboard(rows, models.TieBreakerTime)); !slices.Equal(got, []int{1, 2, 2, 4}) {
+		t.Errorf("time ranks = %v, want [1 2 2 4]", got)
+	}
+	if got := ranks(rankLeaderboard(rows, models.TieBreakerShared)); !slices.Equal(got, []int{1, 1, 1, 4}) {
+		t.Errorf("shared ranks = %v, want [1 1 1 4]", got)
+	}
+
+	rows[0].tiebreak = 1
+	if got := ranks(rankLeaderboard(rows, models.TieBreakerSuddenDeath)); !slices.Equal(got, []int{1, 2, 2, 4}) {
+		t.Errorf("sudden_death ranks = %v, want [1 2 2 4]", got)
+	}
+}
+
+func TestSortLeaderboard(t *testing.T) {
+	first := time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC)
+	rows := []leaderboardRow{
+		{entry: models.LeaderboardEntry{Name: "Idle", Score: 0}, answerMs: 40000},
+		{entry: models.LeaderboardEntry{Name: "Slow", Score: 0}, answerMs: 18000},
+		{entry: models.LeaderboardEntry{Name: "Fast", Score: 0}, answerMs: 6000},
+		{entry: models.LeaderboardEntry{Name: "Leader", Score: 1200}, answerMs: 30000, firstCorrect: &first},
+		{entry: models.LeaderboardEntry{Name: "Placed", Score: 0}, answerMs: 40000, tiebreak: 1},
+	}
+
+	sortLeaderboard(rows)
+	var got []string
+	for _, r := range rows {
+		got = append(got, r.entry.Name)
+	}
+	if want := []string{"Leader", "Placed", "Fast", "Slow", "Idle"}; !slices.Equal(got, want) {
+		t.Errorf("order = %v, want %v", got, want)
+	}
+}
+
+func TestAddAnswerTimes(t *testing.T) {
+	start := time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC)
+	quick, steady, idle := uuid.New(), uuid.New(), uuid.New()
+	q1, q2 := uuid.NewString(), uuid.NewString()
+	ms := func(n int64) *int64 { return &n }
+	// Quick answered only the first question, in 2s; Steady answered both in
+	// 8s each. Quick has the better average but the worse total once the
+	// missed question counts at its 20s limit.
+	answers := []timedAnswer{
+		{playerID: quick.String(), questionID: q1, answerMs: ms(2000), answeredAt: start.Add(2 * time.Second), limitMs: 20000},
+		{playerID: steady.String(), questionID: q1, answerMs: ms(8000), answeredAt: start.Add(8 * time.Second), correct: true, limitMs: 20000},
+		{playerID: steady.String(), questionID: q2, answerMs: ms(8000), answeredAt: start.Add(time.Minute), correct: true, limitMs: 20000},
+	}
+	rows := []leaderboardRow{
+		{entry: models.LeaderboardEntry{PlayerID: idle, Name: "Idle"}},
+		{entry: models.LeaderboardEntry{PlayerID: quick, Name: "Quick"}},
+		{entry: models.LeaderboardEntry{PlayerID: steady, Name: "Steady"}},
+	}
+
+	addAnswerTimes(rows, answers)
+	sortLeaderboard(rows)
+	var got []string
+	for _, r := range rows {
+		got = append(got, r.entry.Name)
+	}
+	if want := []string{"Steady", "Quick", "Idle"}; !slices.Equal(got, want) {
+		t.Errorf("order = %v, want %v", got, want)
+	}
+	if rows[0].answerMs != 16000 || rows[1].answerMs != 22000 || rows[2].answerMs != 40000 {
+		t.Errorf("totals = %d, %d, %d, want 16000, 22000, 40000", rows[0].answerMs, rows[1].answerMs, rows[2].answerMs)
+	}
+	if rows[0].firstCorrect == nil || !rows[0].firstCorrect.Equal(start.Add(8*time.Second)) {
+		t.Errorf("first correct = %v, want the first answer", rows[0].firstCorrect)
+	}
+	if rows[1].levelWith(rows[2], models.TieBreakerTime) {
+		t.Error("a player who answered should not be level with one who never did")
+	}
+}
+
+func TestPodiumTie(t *testing.T) {
+	ids := []uuid.UUID{uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()}
+	entry := func(i, rank int) models.LeaderboardEntry {
+		return models.LeaderboardEntry{PlayerID: ids[i], Rank: rank}
+	}
+
+	tests := []struct {
+		name    string
+		entries []models.LeaderboardEntry
+		want    []string
+	}{
+		{"tie for third", []models.LeaderboardEntry{entry(0, 1), entry(1, 2), entry(2, 3), entry(3, 3)}, []string{ids[2].String(), ids[3].String()}},
+		{"tie for first", []models.LeaderboardEntry{entry(0, 1), entry(1, 1), entry(2, 3), entry(3, 3)}, []string{ids[0].String(), ids[1].String()}},
+		{"tie below podium", []models.LeaderboardEntry{entry(0, 1), entry(1, 2), entry(2, 3), entry(3, 4), entry(4, 4)}, nil},
+		{"no ties", []models.LeaderboardEntry{entry(0, 1), entry(1, 2)}, nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := podiumTie(tc.entries); !slices.Equal(got, tc.want) {
+				t.Errorf("podiumTie = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSuddenDeathPlacings(t *testing.T) {
+	q := storedQuestion{
+		ID:      "q",
+		Options: []storedOption{{ID: "o1"}, {ID: "o2", IsCorrect: true}},
+	}
+	at := time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC)
+	answers := map[string]playerAnswer{
+		"slow":  {OptionID: "o2", AnsweredAt: at.Add(2 * time.Second)},
+		"fast":  {OptionID: "o2", AnsweredAt: at},
+		"wrong": {OptionID: "o1", AnsweredAt: at.Add(-time.Second)},
+	}
+	if got := suddenDeathPlacings(q, answers); !slices.Equal(got, []string{"fast", "slow"}) {
+		t.Errorf("placings = %v, want [fast slow]", got)
+	}
+}
+
+func TestSettleTie(t *testing.T) {
+	// a, b and c tied for first; b answered correctly.
+	settled := settleTie(map[string]int{"a": 0, "b": 0, "c": 0}, []string{"a", "b", "c"}, []string{"b"})
+	if settled["b"] <= settled["a"] || settled["a"] != settled["c"] {
+		t.Errorf("first sudden death = %v, want b above a and c level", settled)
+	}
+
+	// A second sudden death between a and c must keep them below b.
+	settled = settleTie(settled, []string{"a", "c"}, []string{"c"})
+	if !(settled["b"] > settled["c"] && settled["c"] > settled["a"]) {
+		t.Errorf("second sudden death = %v, want b > c > a", settled)
+	}
+}
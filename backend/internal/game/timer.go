@@ -0,0 +1,187 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/HassanA01/Iftarootv2/backend/internal/hub"
+)
+
+// MaxExtendSeconds bounds how much time the host can add in one go.
+const MaxExtendSeconds = 120
+
+// startTimer (re)arms the question timer for a session, replacing any timer
+// already set. When it fires, the question at idx is revealed if it is still
+// open and not paused.
+func (e *Engine) startTimer(ctx context.Context, sessionCode string, idx int, d time.Duration) {
+	t := timer{Code: sessionCode, Kind: timerQuestion, Index: idx}
+	if err := e.schedule(ctx, t, time.Now().Add(d)); err != nil {
+		log.Printf("engine: start timer error: %v", err)
+	}
+}
+
+// cancelTimer cancels the question timer for a session, as when everyone has
+// answered, the host pauses or a player buzzes in.
+func (e *Engine) cancelTimer(ctx context.Context, sessionCode string) {
+	e.unschedule(ctx, sessionCode, timerQuestion)
+}
+
+// questionTimeUp reveals the question at idx when its timer fires, if it is
+// still open and not paused.
+func (e *Engine) questionTimeUp(ctx context.Context, sessionCode string, idx int) error {
+	st, err := e.loadState(ctx, sessionCode)
+	if err != nil || st.CurrentIndex != idx || st.Phase != PhaseQuestion || st.Paused {
+		return nil
+	}
+	return e.triggerReveal(ctx, sessionCode)
+}
+
+// PauseQuestion stops the clock on the open question. Answers are rejected
+// until the host resumes.
+func (e *Engine) PauseQuestion(ctx context.Context, sessionCode string) error {
+	return retryOnConflict(func() error {
+		return e.pauseQuestion(ctx, sessionCode)
+	})
+}
+
+func (e *Engine) pauseQuestion(ctx context.Context, sessionCode string) error {
+	state, err := e.loadState(ctx, sessionCode)
+	if err != nil {
+		return err
+	}
+	if state.Phase != PhaseQuestion {
+		return fmt.Errorf("can only pause an open question (current: %s)", state.Phase)
+	}
+	if state.Paused {
+		return nil
+	}
+
+	now := time.Now()
+	state.Paused = true
+	state.PausedAt = now
+	state.Remaining = max(state.QuestionDeadline.Sub(now), 0)
+	state.QuestionDeadline = time.Time{}
+	if err := e.saveState(ctx, sessionCode, state); err != nil {
+		return err
+	}
+	e.cancelTimer(ctx, sessionCode)
+	e.broadcastTimer(sessionCode, state)
+	return nil
+}
+
+// ResumeQuestion restarts the clock with the time that was left at pause.
+func (e *Engine) ResumeQuestion(ctx context.Context, sessionCode string) error {
+	return retryOnConflict(func() error {
+		return e.resumeQuestion(ctx, sessionCode)
+	})
+}
+
+func (e *Engine) resumeQuestion(ctx context.Context, sessionCode string) error {
+	state, err := e.loadState(ctx, sessionCode)
+	if err != nil {
+		return err
+	}
+	if state.Phase != PhaseQuestion {
+		return fmt.Errorf("can only resume an open question (current: %s)", state.Phase)
+	}
+	if !state.Paused {
+		return nil
+	}
+
+	now := time.Now()
+	state.Paused = false
+	state.PausedFor += now.Sub(state.PausedAt)
+	state.PausedAt = time.Time{}
+	state.QuestionDeadline = now.Add(state.Remaining)
+	state.Remaining = 0
+	if err := e.saveState(ctx, sessionCode, state); err != nil {
+		return err
+	}
+	e.startTimer(ctx, sessionCode, state.CurrentIndex, state.QuestionDeadline.Sub(now))
+	e.broadcastTimer(sessionCode, state)
+	return nil
+}
+
+// ExtendQuestion adds seconds to the open question, paused or not.
+func (e *Engine) ExtendQuestion(ctx context.Context, sessionCode string, seconds int) error {
+	if seconds <= 0 || seconds > MaxExtendSeconds {
+		return fmt.Errorf("seconds must be between 1 and %d", MaxExtendSeconds)
+	}
+	return retryOnConflict(func() error {
+		return e.extendQuestion(ctx, sessionCode, seconds)
+	})
+}
+
+func (e *Engine) extendQuestion(ctx context.Context, sessionCode string, seconds int) error {
+	state, err := e.loadState(ctx, sessionCode)
+	if err != nil {
+		return err
+	}
+	if state.Phase != PhaseQuestion {
+		return fmt.Errorf("can only extend an open question (current: %s)", state.Phase)
+	}
+
+	extra := time.Duration(seconds) * time.Second
+	state.ExtraSeconds += seconds
+	if state.Paused {
+		state.Remaining += extra
+	} else {
+		state.QuestionDeadline = state.QuestionDeadline.Add(extra)
+	}
+	if err := e.saveState(ctx, sessionCode, state); err != nil {
+		return err
+	}
+	if !state.Paused {
+		e.startTimer(ctx, sessionCode, state.CurrentIndex, time.Until(state.QuestionDeadline))
+	}
+	e.broadcastTimer(sessionCode, state)
+	return nil
+}
+
+// TimerMessage returns the current timer_update for a client joining mid-question,
+// or nil if no question is open.
+func (e *Engine) TimerMessage(ctx context.Context, sessionCode string) (*hub.Message, error) {
+	state, err := e.loadState(ctx, sessionCode)
+	if err != nil {
+		return nil, err
+	}
+	if state.Phase != PhaseQuestion {
+		return nil, nil
+	}
+	msg := hub.Message{Type: hub.MsgTimerUpdate, Payload: buildTimerPayload(state, time.Now())}
+	return &msg, nil
+}
+
+func (e *Engine) broadcastTimer(sessionCode string, state *GameState) {
+	e.hub.Broadcast(sessionCode, hub.Message{
+		Type:    hub.MsgTimerUpdate,
+		Payload: buildTimerPayload(state, time.Now()),
+	})
+}
+
+// buildTimerPayload describes the question clock at now. Clients should count
+// down from remaining_ms rather than trust their own clocks against deadline.
+func buildTimerPayload(state *GameState, now time.Time) map[string]any {
+	remaining := state.Remaining
+	if !state.Paused {
+		remaining = max(state.QuestionDeadline.Sub(now), 0)
+	}
+	payload := map[string]any{
+		"question_index": state.CurrentIndex,
+		"paused":         state.Paused,
+		"remaining_ms":   remaining.Milliseconds(),
+		"extra_seconds":  state.ExtraSeconds,
+	}
+	if !state.Paused {
+		payload["deadline"] = state.QuestionDeadline
+	}
+	return payload
+}
+
+// answerElapsed returns seconds from the question opening to answeredAt,
+// excluding time the question spent paused.
+func (s *GameState) answerElapsed(answeredAt time.Time) float64 {
+	return (answeredAt.Sub(s.QuestionStarted) - s.PausedFor).Seconds()
+}
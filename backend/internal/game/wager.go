@@ -0,0 +1,267 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/HassanA01/Iftarootv2/backend/internal/hub"
+)
+
+const (
+	// WagerSeconds is how long players have to place a stake.
+	WagerSeconds = 15
+	// MinWagerCap lets players with few or no points still stake up to this
+	// much, so a wager question can swing any score.
+	MinWagerCap = BasePoints
+)
+
+// redisKeyWagers returns the Redis key for stakes placed on a question index.
+func redisKeyWagers(code string, idx int) string {
+	return fmt.Sprintf("game:%s:q%d:wagers", code, idx)
+}
+
+// MaxWager is the most a player with score can stake.
+func MaxWager(score int) int {
+	return max(score, MinWagerCap)
+}
+
+// WagerPoints is the score change for a stake of wager by a player who had
+// score before the question. A correct answer wins the stake and anything else
+// loses it; unless negative scores are allowed the loss stops at zero.
+func WagerPoints(correct bool, wager, score int, negative bool) int {
+	if correct {
+		return wager
+	}
+	loss := wager
+	if !negative {
+		loss = min(wager, max(score, 0))
+	}
+	return -loss
+}
+
+// openWager starts the wagering phase for the wager question at idx. Each
+// player is told how much they can stake; the question itself opens when
+// everyone has wagered or WagerSeconds pass.
+func (e *Engine) openWager(ctx context.Context, state *GameState, q storedQuestion, idx int) error {
+	sessionCode := state.SessionCode
+	e.redis.Del(ctx, redisKeyWagers(sessionCode, idx))
+
+	state.CurrentIndex = idx
+	state.Phase = PhaseWager
+	state.WagerDeadline = time.Now().Add(WagerSeconds * time.Second)
+	state.QuestionRun++
+	if err := e.saveState(ctx, sessionCode, state); err != nil {
+		return err
+	}
+
+	scores, err := e.playerScores(ctx, state.SessionID)
+	if err != nil {
+		return err
+	}
+	for _, playerID := range e.hub.RoomPlayerIDs(sessionCode) {
+		e.hub.BroadcastToPlayer(sessionCode, playerID, hub.Message{
+			Type:    hub.MsgWagerOpen,
+			Payload: buildWagerPayload(state, q, scores[playerID], time.Now()),
+		})
+	}
+	e.hub.BroadcastToHost(sessionCode, hub.Message{
+		Type:    hub.MsgWagerOpen,
+		Payload: e.hostWagerPayload(ctx, state, q),
+	})
+
+	return e.schedule(ctx, timer{Code: sessionCode, Kind: timerWager, Run: state.QuestionRun}, state.WagerDeadline)
+}
+
+// SubmitWager records a player's stake on the current wager question. The
+// first stake counts. Once every connected player still in the game has
+// wagered, the question opens.
+func (e *Engine) SubmitWager(ctx context.Context, sessionCode, playerID string, amount int) error {
+	return retryOnConflict(func() error {
+		return e.submitWager(ctx, sessionCode, playerID, amount)
+	})
+}
+
+func (e *Engine) submitWager(ctx context.Context, sessionCode, playerID string, amount int) error {
+	state, err := e.loadState(ctx, sessionCode)
+	if err != nil {
+		return fmt.Errorf("load state: %w", err)
+	}
+	if state.Phase != PhaseWager {
+		return fmt.Errorf("not in wager phase (current: %s)", state.Phase)
+	}
+	if state.IsEliminated(playerID) {
+		return fmt.Errorf("player has been eliminated")
+	}
+
+	var score int
+	if err := e.db.QueryRow(ctx,
+		`SELECT score FROM game_players WHERE id::text = $1 AND session_id = $2`, playerID, state.SessionID,
+	).Scan(&score); err != nil {
+		return fmt.Errorf("load score: %w", err)
+	}
+	if amount < 0 || amount > MaxWager(score) {
+		return fmt.Errorf("wager must be between 0 and %d", MaxWager(score))
+	}
+
+	key := redisKeyWagers(sessionCode, state.CurrentIndex)
+	placed, err := e.setOnce(ctx, state, key, playerID, amount)
+	if err != nil {
+		return err
+	}
+	if !placed {
+		return nil // already wagered
+	}
+
+	wagered, _ := e.redis.HLen(ctx, key).Result()
+	playerCount := e.connectedSurvivors(sessionCode, state)
+	e.hub.BroadcastToPlayer(sessionCode, playerID, hub.Message{
+		Type:    hub.MsgWagerPlaced,
+		Payload: map[string]any{"question_index": state.CurrentIndex, "wager": amount},
+	})
+	e.hub.BroadcastToHost(sessionCode, hub.Message{
+		Type: hub.MsgWagerPlaced,
+		Payload: map[string]any{
+			"question_index": state.CurrentIndex,
+			"player_id":      playerID,
+			"wager":          amount,
+			"wagered":        wagered,
+			"players":        playerCount,
+		},
+	})
+
+	if playerCount > 0 && int(wagered) >= playerCount {
+		return e.closeWager(ctx, sessionCode, state.QuestionRun)
+	}
+	return nil
+}
+
+// closeWager ends the wagering phase of question run and opens the question.
+// It does nothing if that phase has already closed.
+func (e *Engine) closeWager(ctx context.Context, sessionCode string, run int) error {
+	state, err := e.loadState(ctx, sessionCode)
+	if err != nil {
+		return err
+	}
+	if state.Phase != PhaseWager || state.QuestionRun != run {
+		return nil
+	}
+	return e.broadcastQuestion(ctx, sessionCode, state.CurrentIndex)
+}
+
+// WagerMessage returns the wager_open message for a client joining during the
+// wagering phase, or nil if no wager is open.
+func (e *Engine) WagerMessage(ctx context.Context, sessionCode, playerID string, isHost bool) (*hub.Message, error) {
+	state, err := e.loadState(ctx, sessionCode)
+	if err != nil {
+		return nil, err
+	}
+	if state.Phase != PhaseWager {
+		return nil, nil
+	}
+	questions, err := e.loadCachedQuestions(ctx, sessionCode)
+	if err != nil {
+		return nil, err
+	}
+	q := questions[state.CurrentIndex]
+	if isHost {
+		return &hub.Message{Type: hub.MsgWagerOpen, Payload: e.hostWagerPayload(ctx, state, q)}, nil
+	}
+
+	scores, err := e.playerScores(ctx, state.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	payload := buildWagerPayload(state, q, scores[playerID], time.Now())
+	if raw, err := e.redis.HGet(ctx, redisKeyWagers(sessionCode, state.CurrentIndex), playerID).Result(); err == nil {
+		if n, err := strconv.Atoi(raw); err == nil {
+			payload["wager"] = n
+		}
+	}
+	return &hub.Message{Type: hub.MsgWagerOpen, Payload: payload}, nil
+}
+
+// forfeitWagers charges players who staked on the question but did not
+// answer, recording a wrong answer for each so a re-run can undo it.
+func (e *Engine) forfeitWagers(ctx context.Context, state *GameState, q storedQuestion, wagers, scoresBefore map[string]int, answered map[string]revealScoreEntry) map[string]revealScoreEntry {
+	forfeits := make(map[string]revealScoreEntry)
+	now := time.Now()
+	for playerID, wager := range wagers {
+		if _, ok := answered[playerID]; ok || wager == 0 {
+			continue
+		}
+		entry := revealScoreEntry{
+			Points: WagerPoints(false, wager, scoresBefore[playerID], state.Settings.NegativeScores),
+			Wager:  &wager,
+		}
+		// Not answering counts as taking the whole time limit.
+		if err := e.recordAnswer(ctx, state, playerID, q, playerAnswer{AnsweredAt: now}, float64(q.TimeLimit), &entry); err != nil {
+			log.Printf("engine: forfeit wager error: %v", err)
+			continue
+		}
+		forfeits[playerID] = entry
+	}
+	return forfeits
+}
+
+// loadWagers returns each player's stake on question idx.
+func (e *Engine) loadWagers(ctx context.Context, sessionCode string, idx int) map[string]int {
+	wagers := make(map[string]int)
+	raw, err := e.redis.HGetAll(ctx, redisKeyWagers(sessionCode, idx)).Result()
+	if err != nil {
+		return wagers
+	}
+	for playerID, v := range raw {
+		if n, err := strconv.Atoi(v); err == nil {
+			wagers[playerID] = n
+		}
+	}
+	return wagers
+}
+
+// playerScores returns every player's current score in a session.
+func (e *Engine) playerScores(ctx context.Context, sessionID string) (map[string]int, error) {
+	rows, err := e.db.Query(ctx, `SELECT id::text, score FROM game_players WHERE session_id = $1`, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	scores := make(map[string]int)
+	for rows.Next() {
+		var id string
+		var score int
+		if err := rows.Scan(&id, &score); err != nil {
+			return nil, err
+		}
+		scores[id] = score
+	}
+	return scores, rows.Err()
+}
+
+func (e *Engine) hostWagerPayload(ctx context.Context, state *GameState, q storedQuestion) map[string]any {
+	payload := buildWagerPayload(state, q, 0, time.Now())
+	delete(payload, "score")
+	delete(payload, "max_wager")
+	payload["question"] = BuildHostQuestionPayload(q, state.CurrentIndex, state.TotalQuestions)["question"]
+	wagered, _ := e.redis.HLen(ctx, redisKeyWagers(state.SessionCode, state.CurrentIndex)).Result()
+	payload["wagered"] = wagered
+	payload["players"] = e.connectedSurvivors(state.SessionCode, state)
+	return payload
+}
+
+// buildWagerPayload is what a player sees while wagering: the question's type
+// but not its text or options, their score and the most they can stake.
+func buildWagerPayload(state *GameState, q storedQuestion, score int, now time.Time) map[string]any {
+	return map[string]any{
+		"question_index":  state.CurrentIndex,
+		"total_questions": state.TotalQuestions,
+		"question_type":   q.Type,
+		"score":           score,
+		"max_wager":       MaxWager(score),
+		"deadline":        state.WagerDeadline,
+		"remaining_ms":    max(state.WagerDeadline.Sub(now), 0).Milliseconds(),
+	}
+}
@@ -0,0 +1,70 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMaxWager(t *testing.T) {
+	tests := []struct {
+		score int
+		want  int
+	}{
+		{0, MinWagerCap},
+		{-300, MinWagerCap},
+		{MinWagerCap - 1, MinWagerCap},
+		{4200, 4200},
+	}
+	for _, tc := range tests {
+		if got := MaxWager(tc.score); got != tc.want {
+			t.Errorf("MaxWager(%d) = %d, want %d", tc.score, got, tc.want)
+		}
+	}
+}
+
+func TestWagerPoints(t *testing.T) {
+	tests := []struct {
+		name     string
+		correct  bool
+		wager    int
+		score    int
+		negative bool
+		want     int
+	}{
+		{"correct wins the stake", true, 500, 2000, false, 500},
+		{"wrong loses the stake", false, 500, 2000, false, -500},
+		{"loss floored at zero", false, 800, 300, false, -300},
+		{"no points to lose", false, 800, 0, false, 0},
+		{"negative scores allowed", false, 800, 300, true, -800},
+		{"already negative stays put when floored", false, 200, -100, false, 0},
+		{"zero stake", false, 0, 2000, true, 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := WagerPoints(tc.correct, tc.wager, tc.score, tc.negative); got != tc.want {
+				t.Errorf("WagerPoints = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBuildWagerPayload(t *testing.T) {
+	now := time.Now()
+	state := &GameState{
+		CurrentIndex:   2,
+		TotalQuestions: 5,
+		WagerDeadline:  now.Add(10 * time.Second),
+	}
+	q := storedQuestion{ID: "q", Type: "single_choice", Text: "secret", Wager: true}
+
+	payload := buildWagerPayload(state, q, 250, now)
+	if payload["max_wager"] != MinWagerCap {
+		t.Errorf("max_wager = %v, want %d", payload["max_wager"], MinWagerCap)
+	}
+	if payload["remaining_ms"] != int64(10000) {
+		t.Errorf("remaining_ms = %v, want 10000", payload["remaining_ms"])
+	}
+	if _, ok := payload["question"]; ok {
+		t.Error("wager payload must not reveal the question")
+	}
+}
@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	appMiddleware "github.com/HassanA01/Iftarootv2/backend/internal/middleware"
+	"github.com/HassanA01/Iftarootv2/backend/internal/models"
+)
+
+const (
+	maxQuestionTags = 10
+	maxTagLength    = 40
+	maxDrawRules    = 20
+	maxDrawCount    = 100
+)
+
+// normalizeTag lowercases and trims a tag so "Seerah " and "seerah" match.
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// normalizeTags returns tags normalised and deduplicated, or a client-facing
+// message if they are invalid.
+func normalizeTags(tags []string) ([]string, string) {
+	if len(tags) > maxQuestionTags {
+		return nil, fmt.Sprintf("at most %d tags are allowed", maxQuestionTags)
+	}
+	out := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = normalizeTag(tag)
+		if tag == "" {
+			return nil, "tags cannot be empty"
+		}
+		if len([]rune(tag)) > maxTagLength {
+			return nil, fmt.Sprintf("tags must be at most %d characters", maxTagLength)
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			out = append(out, tag)
+		}
+	}
+	return out, ""
+}
+
+// validDifficulty reports whether d is unset or a known difficulty.
+func validDifficulty(d models.Difficulty) bool {
+	switch d {
+	case "", models.DifficultyEasy, models.DifficultyMedium, models.DifficultyHard:
+		return true
+	}
+	return false
+}
+
+// validateDrawRules normalises rule tags in place. It returns a client-facing
+// error message, or "" if the rules are valid.
+func validateDrawRules(rules []models.DrawRule) string {
+	if len(rules) > maxDrawRules {
+		return fmt.Sprintf("at most %d draw_rules are allowed", maxDrawRules)
+	}
+	for i := range rules {
+		rule := &rules[i]
+		if rule.Count < 1 || rule.Count > maxDrawCount {
+			return fmt.Sprintf("draw rule %d: count must be between 1 and %d", i+1, maxDrawCount)
+		}
+		if !validDifficulty(rule.Difficulty) {
+			return fmt.Sprintf("draw rule %d: difficulty must be easy, medium or hard", i+1)
+		}
+		rule.Tag = normalizeTag(rule.Tag)
+		if len([]rune(rule.Tag)) > maxTagLength {
+			return fmt.Sprintf("draw rule %d: tag must be at most %d characters", i+1, maxTagLength)
+		}
+	}
+	return ""
+}
+
+// ListBankQuestions lists the admin's question bank, optionally filtered by
+// ?tag= and ?difficulty=.
+func (h *Handler) ListBankQuestions(w http.ResponseWriter, r *http.Request) {
+	adminID := appMiddleware.GetAdminID(r.Context())
+	tag := normalizeTag(r.URL.Query().Get("tag"))
+	difficulty := models.Difficulty(r.URL.Query().Get("difficulty"))
+	if !validDifficulty(difficulty) {
+		writeError(w, http.StatusBadRequest, "difficulty must be easy, medium or hard")
+		return
+	}
+
+	questions, err := h.queryQuestions(r.Context(),
+		`quiz_id IS NULL AND admin_id = $1
+		   AND ($2 = '' OR $2 = ANY(tags))
+		   AND ($3 = '' OR difficulty = $3)
+		 ORDER BY text, id`,
+		adminID, tag, string(difficulty),
+	)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list questions")
+		return
+	}
+	writeJSON(w, http.StatusOK, questions)
+}
+
+// decodeBankQuestion reads and validates a question bank entry, writing the
+// error response itself if it is invalid.
+func decodeBankQuestion(w http.ResponseWriter, r *http.Request) (*questionInputItem, bool) {
+	var qi questionInputItem
+	if err := json.NewDecoder(r.Body).Decode(&qi); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return nil, false
+	}
+	if qi.Reserve {
+		writeError(w, http.StatusBadRequest, "reserve questions belong to a quiz")
+		return nil, false
+	}
+	questions := []questionInputItem{qi}
+	if msg := validateQuestions(questions); msg != "" {
+		writeError(w, http.StatusBadRequest, strings.TrimPrefix(msg, "question 1: "))
+		return nil, false
+	}
+	return &questions[0], true
+}
+
+func (h *Handler) CreateBankQuestion(w http.ResponseWriter, r *http.Request) {
+	adminID := appMiddleware.GetAdminID(r.Context())
+	qi, ok := decodeBankQuestion(w, r)
+	if !ok {
+		return
+	}
+
+	tx, err := h.db.Begin(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to start transaction")
+		return
+	}
+	defer func() { _ = tx.Rollback(r.Context()) }()
+
+	ids, err := insertQuestions(r.Context(), tx, "", adminID, []questionInputItem{*qi})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to create question")
+		return
+	}
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to commit transaction")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]string{"id": ids[0].String()})
+}
+
+// UpdateBankQuestion edits a bank question in place, keeping its ID so quizzes
+// drawing from the bank and past answers still refer to it. Options are
+// replaced.
+func (h *Handler) UpdateBankQuestion(w http.ResponseWriter, r *http.Request) {
+	questionID := chi.URLParam(r, "questionID")
+	adminID := appMiddleware.GetAdminID(r.Context())
+	qi, ok := decodeBankQuestion(w, r)
+	if !ok {
+		return
+	}
+
+	tx, err := h.db.Begin(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to start transaction")
+		return
+	}
+	defer func() { _ = tx.Rollback(r.Context()) }()
+
+	var qID uuid.UUID
+	err = tx.QueryRow(r.Context(),
+		`UPDATE questions SET type = $1, text = $2, time_limit = $3, "order" = $4, partial_credit = $5,
+		                      accepted_answers = $6, case_sensitive = $7, ignore_diacritics = $8, max_distance = $9,
+		                      numeric_target = $10, numeric_tolerance = $11, scoring_strategy = NULLIF($12, ''),
+		                      points_multiplier = $13, tags = $14, difficulty = NULLIF($15, ''), wager = $16
+		 WHERE id = $17 AND quiz_id IS NULL AND admin_id = $18
+		 RETURNING id`,
+		qi.Type, qi.Text, qi.TimeLimit, qi.Order, qi.PartialCredit,
+		stringsOrEmpty(qi.AcceptedAnswers), qi.CaseSensitive, qi.IgnoreDiacritics, qi.MaxDistance,
+		qi.NumericTarget, qi.NumericTolerance, qi.ScoringStrategy,
+		*qi.PointsMultiplier, stringsOrEmpty(qi.Tags), qi.Difficulty, qi.Wager,
+		questionID, adminID,
+	).Scan(&qID)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "question not found")
+		return
+	}
+
+	if _, err := tx.Exec(r.Context(), `DELETE FROM options WHERE question_id = $1`, questionID); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to update options")
+		return
+	}
+	if err := insertOptions(r.Context(), tx, qID, qi.Options); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to update options")
+		return
+	}
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to commit transaction")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"id": questionID})
+}
+
+func (h *Handler) DeleteBankQuestion(w http.ResponseWriter, r *http.Request) {
+	questionID := chi.URLParam(r, "questionID")
+	adminID := appMiddleware.GetAdminID(r.Context())
+
+	result, err := h.db.Exec(r.Context(),
+		`DELETE FROM questions WHERE id = $1 AND quiz_id IS NULL AND admin_id = $2`, questionID, adminID,
+	)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to delete question")
+		return
+	}
+	if result.RowsAffected() == 0 {
+		writeError(w, http.StatusNotFound, "question not found")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -1,6 +1,9 @@
 package handlers
 
 import (
+	"context"
+	"log"
+
 	"github.com/go-chi/chi/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/redis/go-redis/v9"
@@ -28,6 +31,15 @@ func New(db *pgxpool.Pool, redisClient *redis.Client, gameHub *hub.Hub, cfg *con
 	}
 }
 
+// RunEngine recovers games left in flight by a restart, then fires the game
+// engine's timed transitions until ctx is done.
+func (h *Handler) RunEngine(ctx context.Context) {
+	if err := h.engine.Recover(ctx); err != nil {
+		log.Printf("engine recovery error: %v", err)
+	}
+	h.engine.Run(ctx)
+}
+
 func (h *Handler) RegisterRoutes(r chi.Router) {
 	r.Route("/api/v1", func(r chi.Router) {
 		// Auth
@@ -43,6 +55,12 @@ func (h *Handler) RegisterRoutes(r chi.Router) {
 			r.Put("/quizzes/{quizID}", h.UpdateQuiz)
 			r.Delete("/quizzes/{quizID}", h.DeleteQuiz)
 
+			// Question bank
+			r.Get("/bank/questions", h.ListBankQuestions)
+			r.Post("/bank/questions", h.CreateBankQuestion)
+			r.Put("/bank/questions/{questionID}", h.UpdateBankQuestion)
+			r.Delete("/bank/questions/{questionID}", h.DeleteBankQuestion)
+
 			// Game session management
 			r.Post("/sessions", h.CreateSession)
 			r.Get("/sessions/{sessionID}", h.GetSession)
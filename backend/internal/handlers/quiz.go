@@ -1,12 +1,17 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 
+	"github.com/HassanA01/Iftarootv2/backend/internal/game"
 	appMiddleware "github.com/HassanA01/Iftarootv2/backend/internal/middleware"
 	"github.com/HassanA01/Iftarootv2/backend/internal/models"
 )
@@ -14,7 +19,9 @@ import (
 func (h *Handler) ListQuizzes(w http.ResponseWriter, r *http.Request) {
 	adminID := appMiddleware.GetAdminID(r.Context())
 	rows, err := h.db.Query(r.Context(),
-		`SELECT id, admin_id, title, created_at FROM quizzes WHERE admin_id = $1 ORDER BY created_at DESC`,
+		`SELECT id, admin_id, title, scoring_strategy, streak_schedule, created_at, shuffle_questions, shuffle_options, question_count,
+		        negative_scores, tie_breaker
+		 FROM quizzes WHERE admin_id = $1 ORDER BY created_at DESC`,
 		adminID,
 	)
 	if err != nil {
@@ -26,7 +33,8 @@ func (h *Handler) ListQuizzes(w http.ResponseWriter, r *http.Request) {
 	var quizzes []models.Quiz
 	for rows.Next() {
 		var q models.Quiz
-		if err := rows.Scan(&q.ID, &q.AdminID, &q.Title, &q.CreatedAt); err != nil {
+		if err := rows.Scan(&q.ID, &q.AdminID, &q.Title, &q.ScoringStrategy, &q.StreakSchedule, &q.CreatedAt,
+			&q.ShuffleQuestions, &q.ShuffleOptions, &q.QuestionCount, &q.NegativeScores, &q.TieBreaker); err != nil {
 			writeError(w, http.StatusInternalServerError, "failed to scan quiz")
 			return
 		}
@@ -39,22 +47,390 @@ func (h *Handler) ListQuizzes(w http.ResponseWriter, r *http.Request) {
 }
 
 type createQuizRequest struct {
-	Title     string              `json:"title"`
-	Questions []questionInputItem `json:"questions"`
+	Title           string              `json:"title"`
+	ScoringStrategy string              `json:"scoring_strategy"`
+	StreakSchedule  []int               `json:"streak_schedule"`
+	Questions       []questionInputItem `json:"questions"`
+
+	ShuffleQuestions bool   `json:"shuffle_questions"`
+	ShuffleOptions   string `json:"shuffle_options"`
+	QuestionCount    *int   `json:"question_count"` // draw this many per session; nil or 0 = all
+
+	DrawRules []models.DrawRule `json:"draw_rules"` // question bank draws appended to Questions
+
+	NegativeScores bool `json:"negative_scores"` // lost wagers and penalties may take scores below zero
+
+	TieBreaker string `json:"tie_breaker"` // time (default), shared or sudden_death
+}
+
+// maxStreakScheduleLen bounds how many streak bonus steps a quiz can define.
+const maxStreakScheduleLen = 10
+
+// validateStreakSchedule returns a client-facing error message, or "" if valid.
+func validateStreakSchedule(schedule []int) string {
+	if len(schedule) > maxStreakScheduleLen {
+		return fmt.Sprintf("streak_schedule allows at most %d entries", maxStreakScheduleLen)
+	}
+	for _, bonus := range schedule {
+		if bonus < 0 {
+			return "streak_schedule entries must not be negative"
+		}
+	}
+	return ""
+}
+
+// validateTieBreaker resolves a quiz's tie_breaker, defaulting to time.
+// Sudden death needs at least one reserve question to ask. Returns a
+// client-facing message if invalid.
+func validateTieBreaker(tieBreaker string, questions []questionInputItem) (models.TieBreaker, string) {
+	tb := models.TieBreaker(tieBreaker)
+	switch tb {
+	case "":
+		return models.TieBreakerTime, ""
+	case models.TieBreakerTime, models.TieBreakerShared:
+		return tb, ""
+	case models.TieBreakerSuddenDeath:
+		for _, qi := range questions {
+			if qi.Reserve {
+				return tb, ""
+			}
+		}
+		return "", "sudden_death requires at least one reserve question"
+	}
+	return "", "tie_breaker must be time, shared or sudden_death"
+}
+
+// parseOptionShuffle reports whether s names an option shuffle mode.
+func parseOptionShuffle(s string) (models.OptionShuffle, bool) {
+	switch m := models.OptionShuffle(s); m {
+	case models.OptionShuffleNone, models.OptionShuffleSession, models.OptionShufflePlayer:
+		return m, true
+	}
+	return "", false
+}
+
+// validateShuffle resolves a quiz's shuffle_options (default none) and checks
+// question_count. Returns a client-facing message if invalid.
+func validateShuffle(shuffleOptions string, questionCount *int) (models.OptionShuffle, string) {
+	if questionCount != nil && *questionCount < 0 {
+		return "", "question_count must not be negative"
+	}
+	if shuffleOptions == "" {
+		return models.OptionShuffleNone, ""
+	}
+	mode, ok := parseOptionShuffle(shuffleOptions)
+	if !ok {
+		return "", "shuffle_options must be none, session or player"
+	}
+	return mode, ""
 }
 
 type questionInputItem struct {
-	Text      string            `json:"text"`
-	TimeLimit int               `json:"time_limit"`
-	Order     int               `json:"order"`
-	Options   []optionInputItem `json:"options"`
+	Type             models.QuestionType `json:"type"`
+	Text             string              `json:"text"`
+	TimeLimit        int                 `json:"time_limit"`
+	Order            int                 `json:"order"`
+	PartialCredit    bool                `json:"partial_credit"`
+	ScoringStrategy  string              `json:"scoring_strategy"`  // overrides the quiz's strategy when set
+	PointsMultiplier *int                `json:"points_multiplier"` // 0, 1 or 2; defaults to 1
+	Options          []optionInputItem   `json:"options"`
+
+	AcceptedAnswers  []string `json:"accepted_answers"`
+	CaseSensitive    bool     `json:"case_sensitive"`
+	IgnoreDiacritics bool     `json:"ignore_diacritics"`
+	MaxDistance      int      `json:"max_distance"`
+
+	NumericTarget    *float64 `json:"numeric_target"`
+	NumericTolerance float64  `json:"numeric_tolerance"`
+
+	Tags       []string          `json:"tags"`
+	Difficulty models.Difficulty `json:"difficulty"`
+
+	Wager   bool `json:"wager"`
+	Reserve bool `json:"reserve"` // only asked in sudden death
 }
 
+// maxTypedAnswerDistance bounds the Levenshtein tolerance an author can set.
+const maxTypedAnswerDistance = 5
+
 type optionInputItem struct {
 	Text      string `json:"text"`
 	IsCorrect bool   `json:"is_correct"`
 }
 
+// validateQuestions normalises question types in place and checks that each
+// question's options fit its type. It returns a client-facing error message,
+// or "" if the questions are valid.
+func validateQuestions(questions []questionInputItem) string {
+	for i := range questions {
+		qi := &questions[i]
+		if qi.Type == "" {
+			qi.Type = models.QuestionTypeSingleChoice
+		}
+
+		if qi.ScoringStrategy != "" && !game.IsScoringStrategy(qi.ScoringStrategy) {
+			return fmt.Sprintf("question %d: unknown scoring_strategy %q", i+1, qi.ScoringStrategy)
+		}
+		if qi.PointsMultiplier == nil {
+			one := 1
+			qi.PointsMultiplier = &one
+		}
+		if m := *qi.PointsMultiplier; m < 0 || m > 2 {
+			return fmt.Sprintf("question %d: points_multiplier must be 0, 1 or 2", i+1)
+		}
+
+		correct := 0
+		for _, oi := range qi.Options {
+			if oi.IsCorrect {
+				correct++
+			}
+		}
+
+		switch qi.Type {
+		case models.QuestionTypeSingleChoice:
+			if correct != 1 {
+				return fmt.Sprintf("question %d: single_choice needs exactly one correct option (use poll for unscored votes)", i+1)
+			}
+		case models.QuestionTypePoll:
+			if len(qi.Options) < 2 {
+				return fmt.Sprintf("question %d: poll needs at least two options", i+1)
+			}
+			if correct > 0 {
+				return fmt.Sprintf("question %d: poll options cannot be marked correct", i+1)
+			}
+		case models.QuestionTypeMultiSelect:
+			if len(qi.Options) < 2 {
+				return fmt.Sprintf("question %d: multi_select needs at least two options", i+1)
+			}
+			if correct == 0 {
+				return fmt.Sprintf("question %d: multi_select needs at least one correct option", i+1)
+			}
+		case models.QuestionTypeOrdering:
+			if len(qi.Options) < 2 {
+				return fmt.Sprintf("question %d: ordering needs at least two options", i+1)
+			}
+			if correct > 0 {
+				return fmt.Sprintf("question %d: ordering options are listed in the correct order and cannot be marked correct", i+1)
+			}
+		case models.QuestionTypeTypedAnswer:
+			if len(qi.Options) > 0 {
+				return fmt.Sprintf("question %d: typed_answer does not take options", i+1)
+			}
+			accepted := qi.AcceptedAnswers[:0]
+			for _, a := range qi.AcceptedAnswers {
+				if a = strings.TrimSpace(a); a != "" {
+					accepted = append(accepted, a)
+				}
+			}
+			qi.AcceptedAnswers = accepted
+			if len(qi.AcceptedAnswers) == 0 {
+				return fmt.Sprintf("question %d: typed_answer needs at least one accepted answer", i+1)
+			}
+			if qi.MaxDistance < 0 || qi.MaxDistance > maxTypedAnswerDistance {
+				return fmt.Sprintf("question %d: max_distance must be between 0 and %d", i+1, maxTypedAnswerDistance)
+			}
+		case models.QuestionTypeBuzzer:
+			if len(qi.Options) > 0 {
+				return fmt.Sprintf("question %d: buzzer does not take options", i+1)
+			}
+			// Accepted answers are optional here: they guide the host's ruling.
+			accepted := qi.AcceptedAnswers[:0]
+			for _, a := range qi.AcceptedAnswers {
+				if a = strings.TrimSpace(a); a != "" {
+					accepted = append(accepted, a)
+				}
+			}
+			qi.AcceptedAnswers = accepted
+		case models.QuestionTypeNumeric:
+			if len(qi.Options) > 0 {
+				return fmt.Sprintf("question %d: numeric does not take options", i+1)
+			}
+			if qi.NumericTarget == nil {
+				return fmt.Sprintf("question %d: numeric needs a numeric_target", i+1)
+			}
+			if qi.NumericTolerance < 0 {
+				return fmt.Sprintf("question %d: numeric_tolerance must not be negative", i+1)
+			}
+		default:
+			return fmt.Sprintf("question %d: unknown question type %q", i+1, qi.Type)
+		}
+		if qi.Type != models.QuestionTypeTypedAnswer && qi.Type != models.QuestionTypeBuzzer {
+			qi.AcceptedAnswers = nil
+		}
+		if qi.Type != models.QuestionTypeNumeric {
+			qi.NumericTarget = nil
+			qi.NumericTolerance = 0
+		}
+
+		tags, msg := normalizeTags(qi.Tags)
+		if msg != "" {
+			return fmt.Sprintf("question %d: %s", i+1, msg)
+		}
+		qi.Tags = tags
+		if !validDifficulty(qi.Difficulty) {
+			return fmt.Sprintf("question %d: difficulty must be easy, medium or hard", i+1)
+		}
+		if qi.Wager && qi.Type == models.QuestionTypePoll {
+			return fmt.Sprintf("question %d: polls are unscored and cannot be wagered on", i+1)
+		}
+		if qi.Reserve && (qi.Type == models.QuestionTypePoll || qi.Type == models.QuestionTypeBuzzer) {
+			return fmt.Sprintf("question %d: polls and buzzer questions cannot be reserve questions", i+1)
+		}
+	}
+	return ""
+}
+
+// intsOrEmpty keeps NOT NULL integer array columns satisfied when unset.
+func intsOrEmpty(v []int) []int {
+	if v == nil {
+		return []int{}
+	}
+	return v
+}
+
+// stringsOrEmpty keeps NOT NULL text array columns (accepted_answers, tags)
+// satisfied when unset.
+func stringsOrEmpty(v []string) []string {
+	if v == nil {
+		return []string{}
+	}
+	return v
+}
+
+// insertQuestions writes questions and their options within tx. Quiz
+// questions pass the quiz's ID; question bank entries pass an empty quizID.
+// Returns the new question IDs in input order.
+func insertQuestions(ctx context.Context, tx pgx.Tx, quizID, adminID string, questions []questionInputItem) ([]uuid.UUID, error) {
+	ids := make([]uuid.UUID, 0, len(questions))
+	for _, qi := range questions {
+		qID := uuid.New()
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO questions (id, quiz_id, admin_id, type, text, time_limit, "order", partial_credit,
+			                        accepted_answers, case_sensitive, ignore_diacritics, max_distance,
+			                        numeric_target, numeric_tolerance, scoring_strategy, points_multiplier, tags, difficulty, wager, reserve)
+			 VALUES ($1, NULLIF($2, '')::uuid, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, NULLIF($15, ''), $16, $17,
+			         NULLIF($18, ''), $19, $20)`,
+			qID, quizID, adminID, qi.Type, qi.Text, qi.TimeLimit, qi.Order, qi.PartialCredit,
+			stringsOrEmpty(qi.AcceptedAnswers), qi.CaseSensitive, qi.IgnoreDiacritics, qi.MaxDistance,
+			qi.NumericTarget, qi.NumericTolerance, qi.ScoringStrategy, *qi.PointsMultiplier,
+			stringsOrEmpty(qi.Tags), qi.Difficulty, qi.Wager, qi.Reserve,
+		); err != nil {
+			return nil, fmt.Errorf("insert question: %w", err)
+		}
+		if err := insertOptions(ctx, tx, qID, qi.Options); err != nil {
+			return nil, err
+		}
+		ids = append(ids, qID)
+	}
+	return ids, nil
+}
+
+// insertOptions writes a question's options in display order within tx.
+func insertOptions(ctx context.Context, tx pgx.Tx, questionID uuid.UUID, options []optionInputItem) error {
+	for pos, oi := range options {
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO options (id, question_id, text, is_correct, position) VALUES ($1, $2, $3, $4, $5)`,
+			uuid.New(), questionID, oi.Text, oi.IsCorrect, pos,
+		); err != nil {
+			return fmt.Errorf("insert option: %w", err)
+		}
+	}
+	return nil
+}
+
+// insertDrawRules writes a quiz's question bank draw rules within tx.
+func insertDrawRules(ctx context.Context, tx pgx.Tx, quizID string, rules []models.DrawRule) error {
+	for pos, rule := range rules {
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO quiz_draw_rules (id, quiz_id, position, tag, difficulty, count)
+			 VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), $6)`,
+			uuid.New(), quizID, pos, rule.Tag, rule.Difficulty, rule.Count,
+		); err != nil {
+			return fmt.Errorf("insert draw rule: %w", err)
+		}
+	}
+	return nil
+}
+
+// queryQuestions loads questions matching clause, with their options.
+func (h *Handler) queryQuestions(ctx context.Context, clause string, args ...any) ([]models.Question, error) {
+	rows, err := h.db.Query(ctx,
+		`SELECT id, quiz_id, type, text, time_limit, "order", partial_credit, COALESCE(scoring_strategy, ''), points_multiplier,
+		        accepted_answers, case_sensitive, ignore_diacritics, max_distance,
+		        numeric_target, numeric_tolerance, tags, COALESCE(difficulty, ''), wager, reserve
+		 FROM questions WHERE `+clause, args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	questions := []models.Question{}
+	for rows.Next() {
+		var q models.Question
+		if err := rows.Scan(&q.ID, &q.QuizID, &q.Type, &q.Text, &q.TimeLimit, &q.Order, &q.PartialCredit, &q.ScoringStrategy, &q.PointsMultiplier,
+			&q.AcceptedAnswers, &q.CaseSensitive, &q.IgnoreDiacritics, &q.MaxDistance,
+			&q.NumericTarget, &q.NumericTolerance, &q.Tags, &q.Difficulty, &q.Wager, &q.Reserve); err != nil {
+			return nil, err
+		}
+		questions = append(questions, q)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	for i := range questions {
+		options, err := h.loadOptions(ctx, questions[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		questions[i].Options = options
+	}
+	return questions, nil
+}
+
+func (h *Handler) loadOptions(ctx context.Context, questionID uuid.UUID) ([]models.Option, error) {
+	rows, err := h.db.Query(ctx,
+		`SELECT id, question_id, text, is_correct, position FROM options WHERE question_id = $1 ORDER BY position, id`, questionID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var options []models.Option
+	for rows.Next() {
+		var o models.Option
+		if err := rows.Scan(&o.ID, &o.QuestionID, &o.Text, &o.IsCorrect, &o.Position); err != nil {
+			return nil, err
+		}
+		options = append(options, o)
+	}
+	return options, rows.Err()
+}
+
+func (h *Handler) loadDrawRules(ctx context.Context, quizID string) ([]models.DrawRule, error) {
+	rows, err := h.db.Query(ctx,
+		`SELECT COALESCE(tag, ''), COALESCE(difficulty, ''), count FROM quiz_draw_rules
+		 WHERE quiz_id = $1 ORDER BY position`, quizID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []models.DrawRule
+	for rows.Next() {
+		var rule models.DrawRule
+		if err := rows.Scan(&rule.Tag, &rule.Difficulty, &rule.Count); err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
 func (h *Handler) CreateQuiz(w http.ResponseWriter, r *http.Request) {
 	adminID := appMiddleware.GetAdminID(r.Context())
 
@@ -67,6 +443,35 @@ func (h *Handler) CreateQuiz(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "title is required")
 		return
 	}
+	if req.ScoringStrategy == "" {
+		req.ScoringStrategy = game.DefaultScoringStrategy
+	}
+	if !game.IsScoringStrategy(req.ScoringStrategy) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown scoring_strategy %q", req.ScoringStrategy))
+		return
+	}
+	if msg := validateStreakSchedule(req.StreakSchedule); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	shuffleOptions, msg := validateShuffle(req.ShuffleOptions, req.QuestionCount)
+	if msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	if msg := validateQuestions(req.Questions); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	if msg := validateDrawRules(req.DrawRules); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	tieBreaker, msg := validateTieBreaker(req.TieBreaker, req.Questions)
+	if msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
 
 	quizID := uuid.New()
 	adminUUID, err := uuid.Parse(adminID)
@@ -83,34 +488,24 @@ func (h *Handler) CreateQuiz(w http.ResponseWriter, r *http.Request) {
 	defer func() { _ = tx.Rollback(r.Context()) }()
 
 	_, err = tx.Exec(r.Context(),
-		`INSERT INTO quizzes (id, admin_id, title) VALUES ($1, $2, $3)`,
-		quizID, adminUUID, req.Title,
+		`INSERT INTO quizzes (id, admin_id, title, scoring_strategy, streak_schedule, shuffle_questions, shuffle_options, question_count,
+		                      negative_scores, tie_breaker)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		quizID, adminUUID, req.Title, req.ScoringStrategy, intsOrEmpty(req.StreakSchedule),
+		req.ShuffleQuestions, shuffleOptions, req.QuestionCount, req.NegativeScores, tieBreaker,
 	)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "failed to create quiz")
 		return
 	}
 
-	for _, qi := range req.Questions {
-		qID := uuid.New()
-		_, err = tx.Exec(r.Context(),
-			`INSERT INTO questions (id, quiz_id, text, time_limit, "order") VALUES ($1, $2, $3, $4, $5)`,
-			qID, quizID, qi.Text, qi.TimeLimit, qi.Order,
-		)
-		if err != nil {
-			writeError(w, http.StatusInternalServerError, "failed to create question")
-			return
-		}
-		for _, oi := range qi.Options {
-			_, err = tx.Exec(r.Context(),
-				`INSERT INTO options (id, question_id, text, is_correct) VALUES ($1, $2, $3, $4)`,
-				uuid.New(), qID, oi.Text, oi.IsCorrect,
-			)
-			if err != nil {
-				writeError(w, http.StatusInternalServerError, "failed to create option")
-				return
-			}
-		}
+	if _, err := insertQuestions(r.Context(), tx, quizID.String(), adminID, req.Questions); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to create questions")
+		return
+	}
+	if err := insertDrawRules(r.Context(), tx, quizID.String(), req.DrawRules); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to create draw rules")
+		return
 	}
 
 	if err := tx.Commit(r.Context()); err != nil {
@@ -127,55 +522,29 @@ func (h *Handler) GetQuiz(w http.ResponseWriter, r *http.Request) {
 
 	var quiz models.Quiz
 	err := h.db.QueryRow(r.Context(),
-		`SELECT id, admin_id, title, created_at FROM quizzes WHERE id = $1 AND admin_id = $2`, quizID, adminID,
-	).Scan(&quiz.ID, &quiz.AdminID, &quiz.Title, &quiz.CreatedAt)
+		`SELECT id, admin_id, title, scoring_strategy, streak_schedule, created_at, shuffle_questions, shuffle_options, question_count,
+		        negative_scores, tie_breaker
+		 FROM quizzes WHERE id = $1 AND admin_id = $2`, quizID, adminID,
+	).Scan(&quiz.ID, &quiz.AdminID, &quiz.Title, &quiz.ScoringStrategy, &quiz.StreakSchedule, &quiz.CreatedAt,
+		&quiz.ShuffleQuestions, &quiz.ShuffleOptions, &quiz.QuestionCount, &quiz.NegativeScores, &quiz.TieBreaker)
 	if err != nil {
 		writeError(w, http.StatusNotFound, "quiz not found")
 		return
 	}
 
-	rows, err := h.db.Query(r.Context(),
-		`SELECT id, quiz_id, text, time_limit, "order" FROM questions WHERE quiz_id = $1 ORDER BY "order"`, quizID,
-	)
+	questions, err := h.queryQuestions(r.Context(), `quiz_id = $1 ORDER BY "order"`, quizID)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "failed to load questions")
 		return
 	}
-	defer rows.Close()
+	quiz.Questions = questions
 
-	for rows.Next() {
-		var q models.Question
-		if err := rows.Scan(&q.ID, &q.QuizID, &q.Text, &q.TimeLimit, &q.Order); err != nil {
-			writeError(w, http.StatusInternalServerError, "failed to scan question")
-			return
-		}
-		optRows, err := h.db.Query(r.Context(),
-			`SELECT id, question_id, text, is_correct FROM options WHERE question_id = $1`, q.ID,
-		)
-		if err != nil {
-			writeError(w, http.StatusInternalServerError, "failed to load options")
-			return
-		}
-		for optRows.Next() {
-			var o models.Option
-			if err := optRows.Scan(&o.ID, &o.QuestionID, &o.Text, &o.IsCorrect); err != nil {
-				optRows.Close()
-				writeError(w, http.StatusInternalServerError, "failed to scan option")
-				return
-			}
-			q.Options = append(q.Options, o)
-		}
-		optRows.Close()
-		if err := optRows.Err(); err != nil {
-			writeError(w, http.StatusInternalServerError, "failed to read options")
-			return
-		}
-		quiz.Questions = append(quiz.Questions, q)
-	}
-	if err := rows.Err(); err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to read questions")
+	rules, err := h.loadDrawRules(r.Context(), quizID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to load draw rules")
 		return
 	}
+	quiz.DrawRules = rules
 
 	writeJSON(w, http.StatusOK, quiz)
 }
@@ -193,6 +562,35 @@ func (h *Handler) UpdateQuiz(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "title is required")
 		return
 	}
+	if req.ScoringStrategy == "" {
+		req.ScoringStrategy = game.DefaultScoringStrategy
+	}
+	if !game.IsScoringStrategy(req.ScoringStrategy) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown scoring_strategy %q", req.ScoringStrategy))
+		return
+	}
+	if msg := validateStreakSchedule(req.StreakSchedule); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	shuffleOptions, msg := validateShuffle(req.ShuffleOptions, req.QuestionCount)
+	if msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	if msg := validateQuestions(req.Questions); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	if msg := validateDrawRules(req.DrawRules); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	tieBreaker, msg := validateTieBreaker(req.TieBreaker, req.Questions)
+	if msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
 
 	tx, err := h.db.Begin(r.Context())
 	if err != nil {
@@ -203,8 +601,12 @@ func (h *Handler) UpdateQuiz(w http.ResponseWriter, r *http.Request) {
 
 	// Verify ownership and update title atomically
 	result, err := tx.Exec(r.Context(),
-		`UPDATE quizzes SET title = $1 WHERE id = $2 AND admin_id = $3`,
-		req.Title, quizID, adminID,
+		`UPDATE quizzes SET title = $1, scoring_strategy = $2, streak_schedule = $3,
+		                    shuffle_questions = $4, shuffle_options = $5, question_count = $6, negative_scores = $7,
+		                    tie_breaker = $8
+		 WHERE id = $9 AND admin_id = $10`,
+		req.Title, req.ScoringStrategy, intsOrEmpty(req.StreakSchedule),
+		req.ShuffleQuestions, shuffleOptions, req.QuestionCount, req.NegativeScores, tieBreaker, quizID, adminID,
 	)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "failed to update quiz")
@@ -221,24 +623,18 @@ func (h *Handler) UpdateQuiz(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	for _, qi := range req.Questions {
-		qID := uuid.New()
-		if _, err = tx.Exec(r.Context(),
-			`INSERT INTO questions (id, quiz_id, text, time_limit, "order") VALUES ($1, $2, $3, $4, $5)`,
-			qID, quizID, qi.Text, qi.TimeLimit, qi.Order,
-		); err != nil {
-			writeError(w, http.StatusInternalServerError, "failed to update question")
-			return
-		}
-		for _, oi := range qi.Options {
-			if _, err = tx.Exec(r.Context(),
-				`INSERT INTO options (id, question_id, text, is_correct) VALUES ($1, $2, $3, $4)`,
-				uuid.New(), qID, oi.Text, oi.IsCorrect,
-			); err != nil {
-				writeError(w, http.StatusInternalServerError, "failed to update option")
-				return
-			}
-		}
+	if _, err := insertQuestions(r.Context(), tx, quizID, adminID, req.Questions); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to update questions")
+		return
+	}
+
+	if _, err = tx.Exec(r.Context(), `DELETE FROM quiz_draw_rules WHERE quiz_id = $1`, quizID); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to update draw rules")
+		return
+	}
+	if err := insertDrawRules(r.Context(), tx, quizID, req.DrawRules); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to update draw rules")
+		return
 	}
 
 	if err := tx.Commit(r.Context()); err != nil {
@@ -29,6 +29,174 @@ func TestCreateQuiz_Validation(t *testing.T) {
 	}{
 		{"empty body", mustMarshal(map[string]string{}), http.StatusBadRequest},
 		{"missing title", mustMarshal(map[string]any{"questions": []any{}}), http.StatusBadRequest},
+		{"unknown scoring strategy", mustMarshal(map[string]any{
+			"title":            "Quiz",
+			"scoring_strategy": "random",
+		}), http.StatusBadRequest},
+		{"unknown question scoring strategy", mustMarshal(map[string]any{
+			"title":     "Quiz",
+			"questions": []any{map[string]any{"text": "Q", "scoring_strategy": "random"}},
+		}), http.StatusBadRequest},
+		{"invalid points multiplier", mustMarshal(map[string]any{
+			"title":     "Quiz",
+			"questions": []any{map[string]any{"text": "Q", "points_multiplier": 3}},
+		}), http.StatusBadRequest},
+		{"negative streak bonus", mustMarshal(map[string]any{
+			"title":           "Quiz",
+			"streak_schedule": []int{0, -100},
+		}), http.StatusBadRequest},
+		{"unknown shuffle_options", mustMarshal(map[string]any{
+			"title":           "Quiz",
+			"shuffle_options": "always",
+		}), http.StatusBadRequest},
+		{"negative question_count", mustMarshal(map[string]any{
+			"title":          "Quiz",
+			"question_count": -1,
+		}), http.StatusBadRequest},
+		{"unknown question type", mustMarshal(map[string]any{
+			"title":     "Quiz",
+			"questions": []any{map[string]any{"text": "Q", "type": "essay"}},
+		}), http.StatusBadRequest},
+		{"single choice with two correct", mustMarshal(map[string]any{
+			"title": "Quiz",
+			"questions": []any{map[string]any{
+				"text": "Q",
+				"options": []any{
+					map[string]any{"text": "A", "is_correct": true},
+					map[string]any{"text": "B", "is_correct": true},
+				},
+			}},
+		}), http.StatusBadRequest},
+		{"single choice without correct option", mustMarshal(map[string]any{
+			"title": "Quiz",
+			"questions": []any{map[string]any{
+				"text": "Q",
+				"options": []any{
+					map[string]any{"text": "A"},
+					map[string]any{"text": "B"},
+				},
+			}},
+		}), http.StatusBadRequest},
+		{"poll with correct option", mustMarshal(map[string]any{
+			"title": "Quiz",
+			"questions": []any{map[string]any{
+				"text": "Q",
+				"type": "poll",
+				"options": []any{
+					map[string]any{"text": "A", "is_correct": true},
+					map[string]any{"text": "B"},
+				},
+			}},
+		}), http.StatusBadRequest},
+		{"multi select without correct option", mustMarshal(map[string]any{
+			"title": "Quiz",
+			"questions": []any{map[string]any{
+				"text": "Q",
+				"type": "multi_select",
+				"options": []any{
+					map[string]any{"text": "A"},
+					map[string]any{"text": "B"},
+				},
+			}},
+		}), http.StatusBadRequest},
+		{"typed answer without accepted answers", mustMarshal(map[string]any{
+			"title": "Quiz",
+			"questions": []any{map[string]any{
+				"text":             "Q",
+				"type":             "typed_answer",
+				"accepted_answers": []string{"  "},
+			}},
+		}), http.StatusBadRequest},
+		{"typed answer with excessive tolerance", mustMarshal(map[string]any{
+			"title": "Quiz",
+			"questions": []any{map[string]any{
+				"text":             "Q",
+				"type":             "typed_answer",
+				"accepted_answers": []string{"Makkah"},
+				"max_distance":     10,
+			}},
+		}), http.StatusBadRequest},
+		{"ordering with marked correct option", mustMarshal(map[string]any{
+			"title": "Quiz",
+			"questions": []any{map[string]any{
+				"text": "Q",
+				"type": "ordering",
+				"options": []any{
+					map[string]any{"text": "A", "is_correct": true},
+					map[string]any{"text": "B"},
+				},
+			}},
+		}), http.StatusBadRequest},
+		{"numeric without target", mustMarshal(map[string]any{
+			"title":     "Quiz",
+			"questions": []any{map[string]any{"text": "Q", "type": "numeric"}},
+		}), http.StatusBadRequest},
+		{"numeric with negative tolerance", mustMarshal(map[string]any{
+			"title": "Quiz",
+			"questions": []any{map[string]any{
+				"text":              "Q",
+				"type":              "numeric",
+				"numeric_target":    622,
+				"numeric_tolerance": -1,
+			}},
+		}), http.StatusBadRequest},
+		{"unknown difficulty", mustMarshal(map[string]any{
+			"title":     "Quiz",
+			"questions": []any{map[string]any{"text": "Q", "difficulty": "extreme"}},
+		}), http.StatusBadRequest},
+		{"empty tag", mustMarshal(map[string]any{
+			"title":     "Quiz",
+			"questions": []any{map[string]any{"text": "Q", "tags": []string{"seerah", " "}}},
+		}), http.StatusBadRequest},
+		{"buzzer with options", mustMarshal(map[string]any{
+			"title": "Quiz",
+			"questions": []any{map[string]any{
+				"text":    "Q",
+				"type":    "buzzer",
+				"options": []any{map[string]any{"text": "A", "is_correct": true}},
+			}},
+		}), http.StatusBadRequest},
+		{"wager on a poll", mustMarshal(map[string]any{
+			"title": "Quiz",
+			"questions": []any{map[string]any{
+				"text":  "Q",
+				"type":  "poll",
+				"wager": true,
+				"options": []any{
+					map[string]any{"text": "A"},
+					map[string]any{"text": "B"},
+				},
+			}},
+		}), http.StatusBadRequest},
+		{"draw rule without count", mustMarshal(map[string]any{
+			"title":      "Quiz",
+			"draw_rules": []any{map[string]any{"tag": "seerah"}},
+		}), http.StatusBadRequest},
+		{"draw rule with unknown difficulty", mustMarshal(map[string]any{
+			"title":      "Quiz",
+			"draw_rules": []any{map[string]any{"difficulty": "extreme", "count": 5}},
+		}), http.StatusBadRequest},
+		{"unknown tie_breaker", mustMarshal(map[string]any{
+			"title":       "Quiz",
+			"tie_breaker": "coin_toss",
+		}), http.StatusBadRequest},
+		{"sudden_death without reserve question", mustMarshal(map[string]any{
+			"title":       "Quiz",
+			"tie_breaker": "sudden_death",
+			"questions":   []any{map[string]any{"text": "Q"}},
+		}), http.StatusBadRequest},
+		{"reserve poll", mustMarshal(map[string]any{
+			"title": "Quiz",
+			"questions": []any{map[string]any{
+				"text":    "Q",
+				"type":    "poll",
+				"reserve": true,
+				"options": []any{
+					map[string]any{"text": "A"},
+					map[string]any{"text": "B"},
+				},
+			}},
+		}), http.StatusBadRequest},
 	}
 
 	for _, tc := range tests {
@@ -71,3 +239,48 @@ func TestUpdateQuiz_Validation(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeTags(t *testing.T) {
+	got, msg := normalizeTags([]string{" Seerah", "seerah", "FIQH "})
+	if msg != "" {
+		t.Fatalf("unexpected error %q", msg)
+	}
+	if len(got) != 2 || got[0] != "seerah" || got[1] != "fiqh" {
+		t.Errorf("expected [seerah fiqh], got %v", got)
+	}
+
+	tooMany := make([]string, maxQuestionTags+1)
+	for i := range tooMany {
+		tooMany[i] = string(rune('a' + i))
+	}
+	if _, msg := normalizeTags(tooMany); msg == "" {
+		t.Errorf("expected error for %d tags", len(tooMany))
+	}
+}
+
+func TestCreateBankQuestion_Validation(t *testing.T) {
+	h := newTestHandler()
+
+	tests := []struct {
+		name       string
+		body       []byte
+		wantStatus int
+	}{
+		{"invalid json", []byte("not-json"), http.StatusBadRequest},
+		{"unknown question type", mustMarshal(map[string]any{"text": "Q", "type": "essay"}), http.StatusBadRequest},
+		{"unknown difficulty", mustMarshal(map[string]any{"text": "Q", "difficulty": "extreme"}), http.StatusBadRequest},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			req = withAdminID(req, "test-admin-id")
+			w := httptest.NewRecorder()
+			h.CreateBankQuestion(w, req)
+			if w.Code != tc.wantStatus {
+				t.Errorf("expected %d, got %d — body: %s", tc.wantStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
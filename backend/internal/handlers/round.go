@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/HassanA01/Iftarootv2/backend/internal/game"
+	"github.com/HassanA01/Iftarootv2/backend/internal/models"
+)
+
+const (
+	maxRounds           = 10
+	maxRoundTitleLength = 80
+)
+
+// validateRounds checks the rounds of a new session, trimming titles and
+// numbering positions in place. Returns a client-facing message if the
+// rounds are invalid.
+func validateRounds(rounds []models.SessionRound, mode models.SessionMode) string {
+	if len(rounds) == 0 {
+		return ""
+	}
+	if mode == models.SessionModeSelfPaced {
+		return "rounds are only available in live sessions"
+	}
+	if len(rounds) > maxRounds {
+		return fmt.Sprintf("at most %d rounds are allowed", maxRounds)
+	}
+	for i := range rounds {
+		r := &rounds[i]
+		r.Position = i
+		r.Title = strings.TrimSpace(r.Title)
+		if r.Title == "" {
+			return fmt.Sprintf("round %d: title is required", i+1)
+		}
+		if len([]rune(r.Title)) > maxRoundTitleLength {
+			return fmt.Sprintf("round %d: title must be at most %d characters", i+1, maxRoundTitleLength)
+		}
+		if r.QuestionCount != nil && *r.QuestionCount < 0 {
+			return fmt.Sprintf("round %d: question_count must not be negative", i+1)
+		}
+		if r.ScoringStrategy != nil && !game.IsScoringStrategy(*r.ScoringStrategy) {
+			return fmt.Sprintf("round %d: unknown scoring_strategy %q", i+1, *r.ScoringStrategy)
+		}
+		if r.PointsMultiplier != nil && (*r.PointsMultiplier < 0 || *r.PointsMultiplier > 2) {
+			return fmt.Sprintf("round %d: points_multiplier must be 0, 1 or 2", i+1)
+		}
+	}
+	return ""
+}
+
+// roundQuizzesOwned reports whether every quiz the rounds draw from belongs
+// to adminID.
+func (h *Handler) roundQuizzesOwned(ctx context.Context, adminID string, rounds []models.SessionRound) (bool, error) {
+	var ids []string
+	for _, r := range rounds {
+		if r.QuizID != nil {
+			ids = append(ids, r.QuizID.String())
+		}
+	}
+	if len(ids) == 0 {
+		return true, nil
+	}
+	var missing bool
+	err := h.db.QueryRow(ctx,
+		`SELECT EXISTS(
+		     SELECT 1 FROM unnest($1::uuid[]) AS r(id)
+		     WHERE NOT EXISTS (SELECT 1 FROM quizzes q WHERE q.id = r.id AND q.admin_id = $2)
+		 )`,
+		ids, adminID,
+	).Scan(&missing)
+	return !missing, err
+}
+
+// insertRounds creates a session's rounds within tx.
+func insertRounds(ctx context.Context, tx pgx.Tx, sessionID uuid.UUID, rounds []models.SessionRound) error {
+	for _, r := range rounds {
+		multiplier := 1
+		if r.PointsMultiplier != nil {
+			multiplier = *r.PointsMultiplier
+		}
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO game_session_rounds (session_id, position, title, quiz_id, question_count, scoring_strategy, points_multiplier)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			sessionID, r.Position, r.Title, r.QuizID, r.QuestionCount, r.ScoringStrategy, multiplier,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadRounds returns a session's rounds in play order.
+func (h *Handler) loadRounds(ctx context.Context, sessionID uuid.UUID) ([]models.SessionRound, error) {
+	rows, err := h.db.Query(ctx,
+		`SELECT position, title, quiz_id, question_count, scoring_strategy, points_multiplier
+		 FROM game_session_rounds WHERE session_id = $1 ORDER BY position`,
+		sessionID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rounds []models.SessionRound
+	for rows.Next() {
+		var r models.SessionRound
+		if err := rows.Scan(&r.Position, &r.Title, &r.QuizID, &r.QuestionCount, &r.ScoringStrategy, &r.PointsMultiplier); err != nil {
+			return nil, err
+		}
+		rounds = append(rounds, r)
+	}
+	return rounds, rows.Err()
+}
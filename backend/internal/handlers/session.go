@@ -15,16 +15,45 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgconn"
 
+	"github.com/HassanA01/Iftarootv2/backend/internal/game"
 	"github.com/HassanA01/Iftarootv2/backend/internal/hub"
 	appMiddleware "github.com/HassanA01/Iftarootv2/backend/internal/middleware"
 	"github.com/HassanA01/Iftarootv2/backend/internal/models"
 )
 
+// sessionColumns are the game_sessions columns read into models.GameSession,
+// in the order expected by sessionScanDest.
+const sessionColumns = `id, quiz_id, code, status, started_at, ended_at, created_at, auto_advance, auto_advance_delay,
+	mode, closes_at, team_assignment, team_scoring, elimination, elimination_count,
+	shuffle_questions, shuffle_options, question_count, lives`
+
+func sessionScanDest(s *models.GameSession) []any {
+	return []any{&s.ID, &s.QuizID, &s.Code, &s.Status, &s.StartedAt, &s.EndedAt, &s.CreatedAt,
+		&s.AutoAdvance, &s.AutoAdvanceDelay, &s.Mode, &s.ClosesAt, &s.TeamAssignment, &s.TeamScoring,
+		&s.Elimination, &s.EliminationCount, &s.ShuffleQuestions, &s.ShuffleOptions, &s.QuestionCount, &s.Lives}
+}
+
 func (h *Handler) CreateSession(w http.ResponseWriter, r *http.Request) {
 	adminID := appMiddleware.GetAdminID(r.Context())
 
 	var req struct {
-		QuizID string `json:"quiz_id"`
+		QuizID           string     `json:"quiz_id"`
+		AutoAdvance      bool       `json:"auto_advance"`
+		AutoAdvanceDelay *int       `json:"auto_advance_delay"`
+		Mode             string     `json:"mode"`
+		ClosesAt         *time.Time `json:"closes_at"`
+		TeamAssignment   string     `json:"team_assignment"`
+		TeamScoring      string     `json:"team_scoring"`
+		Teams            []string   `json:"teams"`
+		Elimination      string     `json:"elimination"`
+		EliminationCount *int       `json:"elimination_count"`
+		Lives            *int       `json:"lives"`
+		// Overrides for the quiz's shuffle settings; omitted means use the quiz's.
+		ShuffleQuestions *bool   `json:"shuffle_questions"`
+		ShuffleOptions   *string `json:"shuffle_options"`
+		QuestionCount    *int    `json:"question_count"`
+
+		Rounds []models.SessionRound `json:"rounds"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid request body")
@@ -34,6 +63,52 @@ func (h *Handler) CreateSession(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "quiz_id is required")
 		return
 	}
+	delay := game.DefaultAutoAdvanceDelay
+	if req.AutoAdvanceDelay != nil {
+		delay = *req.AutoAdvanceDelay
+	}
+	if delay < game.MinAutoAdvanceDelay || delay > game.MaxAutoAdvanceDelay {
+		writeError(w, http.StatusBadRequest,
+			fmt.Sprintf("auto_advance_delay must be between %d and %d seconds", game.MinAutoAdvanceDelay, game.MaxAutoAdvanceDelay))
+		return
+	}
+	mode, msg := validateSessionMode(req.Mode, req.ClosesAt, time.Now())
+	if msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	teamAssignment, teamScoring, msg := validateTeams(req.TeamAssignment, req.TeamScoring, req.Teams)
+	if msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	elimination, eliminationCount, msg := validateElimination(req.Elimination, req.EliminationCount, mode)
+	if msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	lives, msg := validateLives(elimination, req.Lives)
+	if msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	var shuffleOptions *models.OptionShuffle
+	if req.ShuffleOptions != nil {
+		m, ok := parseOptionShuffle(*req.ShuffleOptions)
+		if !ok {
+			writeError(w, http.StatusBadRequest, "shuffle_options must be none, session or player")
+			return
+		}
+		shuffleOptions = &m
+	}
+	if req.QuestionCount != nil && *req.QuestionCount < 0 {
+		writeError(w, http.StatusBadRequest, "question_count must not be negative")
+		return
+	}
+	if msg := validateRounds(req.Rounds, mode); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
 
 	// Verify quiz exists and belongs to this admin
 	var exists bool
@@ -45,6 +120,10 @@ func (h *Handler) CreateSession(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusNotFound, "quiz not found")
 		return
 	}
+	if owned, err := h.roundQuizzesOwned(r.Context(), adminID, req.Rounds); err != nil || !owned {
+		writeError(w, http.StatusNotFound, "round quiz not found")
+		return
+	}
 
 	code, err := generateCode()
 	if err != nil {
@@ -53,14 +132,38 @@ func (h *Handler) CreateSession(w http.ResponseWriter, r *http.Request) {
 	}
 	sessionID := uuid.New()
 
-	_, err = h.db.Exec(r.Context(),
-		`INSERT INTO game_sessions (id, quiz_id, code, status) VALUES ($1, $2, $3, $4)`,
-		sessionID, req.QuizID, code, models.GameStatusWaiting,
+	tx, err := h.db.Begin(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to create session")
+		return
+	}
+	defer func() { _ = tx.Rollback(r.Context()) }()
+
+	_, err = tx.Exec(r.Context(),
+		`INSERT INTO game_sessions (id, quiz_id, code, status, auto_advance, auto_advance_delay, mode, closes_at,
+		                            team_assignment, team_scoring, elimination, elimination_count,
+		                            shuffle_questions, shuffle_options, question_count, lives)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)`,
+		sessionID, req.QuizID, code, models.GameStatusWaiting, req.AutoAdvance, delay, mode, req.ClosesAt,
+		teamAssignment, teamScoring, elimination, eliminationCount,
+		req.ShuffleQuestions, shuffleOptions, req.QuestionCount, lives,
 	)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "failed to create session")
 		return
 	}
+	if err := insertTeams(r.Context(), tx, sessionID, req.Teams); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to create teams")
+		return
+	}
+	if err := insertRounds(r.Context(), tx, sessionID, req.Rounds); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to create rounds")
+		return
+	}
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to create session")
+		return
+	}
 
 	writeJSON(w, http.StatusCreated, map[string]string{
 		"session_id": sessionID.String(),
@@ -72,14 +175,21 @@ func (h *Handler) GetSession(w http.ResponseWriter, r *http.Request) {
 	sessionID := chi.URLParam(r, "sessionID")
 	var session models.GameSession
 	err := h.db.QueryRow(r.Context(),
-		`SELECT id, quiz_id, code, status, started_at, ended_at, created_at FROM game_sessions WHERE id = $1`,
+		`SELECT `+sessionColumns+` FROM game_sessions WHERE id = $1`,
 		sessionID,
-	).Scan(&session.ID, &session.QuizID, &session.Code, &session.Status,
-		&session.StartedAt, &session.EndedAt, &session.CreatedAt)
+	).Scan(sessionScanDest(&session)...)
 	if err != nil {
 		writeError(w, http.StatusNotFound, "session not found")
 		return
 	}
+	if session.Teams, err = h.loadTeams(r.Context(), session.ID); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to load teams")
+		return
+	}
+	if session.Rounds, err = h.loadRounds(r.Context(), session.ID); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to load rounds")
+		return
+	}
 	writeJSON(w, http.StatusOK, session)
 }
 
@@ -112,8 +222,9 @@ func (h *Handler) EndSession(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handler) JoinSession(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		Code string `json:"code"`
-		Name string `json:"name"`
+		Code   string `json:"code"`
+		Name   string `json:"name"`
+		TeamID string `json:"team_id"` // optional; only honoured when players choose teams
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid request body")
@@ -126,18 +237,29 @@ func (h *Handler) JoinSession(w http.ResponseWriter, r *http.Request) {
 
 	var session models.GameSession
 	err := h.db.QueryRow(r.Context(),
-		`SELECT id, quiz_id, code, status FROM game_sessions WHERE code = $1 AND status = $2`,
-		req.Code, models.GameStatusWaiting,
-	).Scan(&session.ID, &session.QuizID, &session.Code, &session.Status)
+		`SELECT `+sessionColumns+` FROM game_sessions
+		 WHERE code = $1 AND (status = $2 OR (status = $3 AND mode = $4 AND closes_at > NOW()))`,
+		req.Code, models.GameStatusWaiting, models.GameStatusActive, models.SessionModeSelfPaced,
+	).Scan(sessionScanDest(&session)...)
 	if err != nil {
 		writeError(w, http.StatusNotFound, "game not found or already started")
 		return
 	}
 
+	teamID, err := h.assignTeam(r.Context(), session, req.TeamID)
+	if errors.Is(err, errTeamNotFound) {
+		writeError(w, http.StatusBadRequest, "team not found in this game")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to assign team")
+		return
+	}
+
 	playerID := uuid.New()
 	_, err = h.db.Exec(r.Context(),
-		`INSERT INTO game_players (id, session_id, name, score) VALUES ($1, $2, $3, 0)`,
-		playerID, session.ID, req.Name,
+		`INSERT INTO game_players (id, session_id, name, score, team_id) VALUES ($1, $2, $3, 0, $4)`,
+		playerID, session.ID, req.Name, teamID,
 	)
 	if err != nil {
 		var pgErr *pgconn.PgError
@@ -149,33 +271,46 @@ func (h *Handler) JoinSession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, map[string]string{
+	resp := map[string]string{
 		"player_id":  playerID.String(),
 		"session_id": session.ID.String(),
 		"code":       session.Code,
 		"name":       req.Name,
-	})
+	}
+	if teamID != nil {
+		resp["team_id"] = teamID.String()
+	}
+	writeJSON(w, http.StatusOK, resp)
 }
 
 func (h *Handler) GetSessionByCode(w http.ResponseWriter, r *http.Request) {
 	code := chi.URLParam(r, "code")
 	var session models.GameSession
 	err := h.db.QueryRow(r.Context(),
-		`SELECT id, quiz_id, code, status, started_at, ended_at, created_at FROM game_sessions WHERE code = $1`,
+		`SELECT `+sessionColumns+` FROM game_sessions WHERE code = $1`,
 		code,
-	).Scan(&session.ID, &session.QuizID, &session.Code, &session.Status,
-		&session.StartedAt, &session.EndedAt, &session.CreatedAt)
+	).Scan(sessionScanDest(&session)...)
 	if err != nil {
 		writeError(w, http.StatusNotFound, "session not found")
 		return
 	}
+	if session.Teams, err = h.loadTeams(r.Context(), session.ID); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to load teams")
+		return
+	}
+	if session.Rounds, err = h.loadRounds(r.Context(), session.ID); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to load rounds")
+		return
+	}
 	writeJSON(w, http.StatusOK, session)
 }
 
 func (h *Handler) ListSessionPlayers(w http.ResponseWriter, r *http.Request) {
 	sessionID := chi.URLParam(r, "sessionID")
 	rows, err := h.db.Query(r.Context(),
-		`SELECT id, session_id, name, score, joined_at FROM game_players WHERE session_id = $1 ORDER BY joined_at ASC`,
+		`SELECT id, session_id, name, score, streak, best_streak, joined_at, answered, answer_time_ms, finished_at, team_id,
+		        eliminated_at, eliminated_on
+		 FROM game_players WHERE session_id = $1 ORDER BY joined_at ASC`,
 		sessionID,
 	)
 	if err != nil {
@@ -187,7 +322,8 @@ func (h *Handler) ListSessionPlayers(w http.ResponseWriter, r *http.Request) {
 	players := make([]models.GamePlayer, 0)
 	for rows.Next() {
 		var p models.GamePlayer
-		if err := rows.Scan(&p.ID, &p.SessionID, &p.Name, &p.Score, &p.JoinedAt); err != nil {
+		if err := rows.Scan(&p.ID, &p.SessionID, &p.Name, &p.Score, &p.Streak, &p.BestStreak, &p.JoinedAt,
+			&p.Answered, &p.AnswerTimeMs, &p.FinishedAt, &p.TeamID, &p.EliminatedAt, &p.EliminatedOn); err != nil {
 			writeError(w, http.StatusInternalServerError, "failed to read players")
 			return
 		}
@@ -203,10 +339,9 @@ func (h *Handler) StartSession(w http.ResponseWriter, r *http.Request) {
 	var session models.GameSession
 	err := h.db.QueryRow(r.Context(),
 		`UPDATE game_sessions SET status = $1, started_at = $2 WHERE id = $3 AND status = $4
-		 RETURNING id, quiz_id, code, status, started_at, ended_at, created_at`,
+		 RETURNING `+sessionColumns,
 		models.GameStatusActive, now, sessionID, models.GameStatusWaiting,
-	).Scan(&session.ID, &session.QuizID, &session.Code, &session.Status,
-		&session.StartedAt, &session.EndedAt, &session.CreatedAt)
+	).Scan(sessionScanDest(&session)...)
 	if err != nil {
 		writeError(w, http.StatusNotFound, "session not found or already started")
 		return
@@ -228,6 +363,83 @@ func (h *Handler) StartSession(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, session)
 }
 
+// validateSessionMode resolves the requested session mode, defaulting to live.
+// Self-paced sessions need a closes_at within game.MaxSelfPacedWindow of now.
+// Returns a client-facing message if the combination is invalid.
+func validateSessionMode(mode string, closesAt *time.Time, now time.Time) (models.SessionMode, string) {
+	switch models.SessionMode(mode) {
+	case "", models.SessionModeLive:
+		if closesAt != nil {
+			return "", "closes_at only applies to self_paced sessions"
+		}
+		return models.SessionModeLive, ""
+	case models.SessionModeSelfPaced:
+		if closesAt == nil {
+			return "", "closes_at is required for self_paced sessions"
+		}
+		if !closesAt.After(now) {
+			return "", "closes_at must be in the future"
+		}
+		if closesAt.Sub(now) > game.MaxSelfPacedWindow {
+			return "", fmt.Sprintf("closes_at must be within %d days", int(game.MaxSelfPacedWindow.Hours()/24))
+		}
+		return models.SessionModeSelfPaced, ""
+	default:
+		return "", "mode must be live or self_paced"
+	}
+}
+
+// maxEliminationCount bounds how many players bottom mode removes per question.
+const maxEliminationCount = 50
+
+// validateElimination resolves elimination settings, defaulting to off and one
+// player per question. Elimination needs everyone on the same question, so it
+// is only available in live sessions.
+func validateElimination(mode string, count *int, sessionMode models.SessionMode) (models.EliminationMode, int, string) {
+	n := 1
+	if count != nil {
+		n = *count
+	}
+	if n < 1 || n > maxEliminationCount {
+		return "", 0, fmt.Sprintf("elimination_count must be between 1 and %d", maxEliminationCount)
+	}
+	m := models.EliminationMode(mode)
+	switch m {
+	case "":
+		return models.EliminationOff, n, ""
+	case models.EliminationOff:
+		return m, n, ""
+	case models.EliminationWrongAnswer, models.EliminationBottom, models.EliminationLives:
+		if sessionMode != models.SessionModeLive {
+			return "", 0, "elimination is only available in live sessions"
+		}
+		return m, n, ""
+	default:
+		return "", 0, "elimination must be off, wrong_answer, bottom or lives"
+	}
+}
+
+// Bounds for the lives each player starts with in lives mode.
+const (
+	defaultLives = 3
+	maxLives     = 10
+)
+
+// validateLives resolves how many lives players start with, defaulting to
+// defaultLives. Lives only apply to lives mode.
+func validateLives(mode models.EliminationMode, lives *int) (int, string) {
+	if lives == nil {
+		return defaultLives, ""
+	}
+	if mode != models.EliminationLives {
+		return 0, "lives require elimination to be lives"
+	}
+	if *lives < 1 || *lives > maxLives {
+		return 0, fmt.Sprintf("lives must be between 1 and %d", maxLives)
+	}
+	return *lives, ""
+}
+
 func generateCode() (string, error) {
 	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
 	if err != nil {
@@ -5,6 +5,8 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+
+	"github.com/HassanA01/Iftarootv2/backend/internal/models"
 )
 
 func TestCreateSession_Validation(t *testing.T) {
@@ -17,6 +19,26 @@ func TestCreateSession_Validation(t *testing.T) {
 	}{
 		{"empty body", map[string]string{}, http.StatusBadRequest},
 		{"missing quiz_id", map[string]string{"quiz_id": ""}, http.StatusBadRequest},
+		{"auto_advance_delay too short", map[string]any{"quiz_id": "q1", "auto_advance": true, "auto_advance_delay": 1}, http.StatusBadRequest},
+		{"auto_advance_delay too long", map[string]any{"quiz_id": "q1", "auto_advance": true, "auto_advance_delay": 600}, http.StatusBadRequest},
+		{"unknown mode", map[string]any{"quiz_id": "q1", "mode": "async"}, http.StatusBadRequest},
+		{"self_paced without closes_at", map[string]any{"quiz_id": "q1", "mode": "self_paced"}, http.StatusBadRequest},
+		{"self_paced closes in past", map[string]any{"quiz_id": "q1", "mode": "self_paced", "closes_at": "2020-01-01T00:00:00Z"}, http.StatusBadRequest},
+		{"live with closes_at", map[string]any{"quiz_id": "q1", "closes_at": "2099-01-01T00:00:00Z"}, http.StatusBadRequest},
+		{"unknown team_assignment", map[string]any{"quiz_id": "q1", "team_assignment": "random"}, http.StatusBadRequest},
+		{"unknown team_scoring", map[string]any{"quiz_id": "q1", "team_assignment": "auto", "team_scoring": "median", "teams": []string{"A", "B"}}, http.StatusBadRequest},
+		{"teams without assignment", map[string]any{"quiz_id": "q1", "teams": []string{"A", "B"}}, http.StatusBadRequest},
+		{"single team", map[string]any{"quiz_id": "q1", "team_assignment": "auto", "teams": []string{"A"}}, http.StatusBadRequest},
+		{"blank team name", map[string]any{"quiz_id": "q1", "team_assignment": "choose", "teams": []string{"A", "  "}}, http.StatusBadRequest},
+		{"duplicate team names", map[string]any{"quiz_id": "q1", "team_assignment": "choose", "teams": []string{"Red", " red"}}, http.StatusBadRequest},
+		{"unknown shuffle_options", map[string]any{"quiz_id": "q1", "shuffle_options": "always"}, http.StatusBadRequest},
+		{"negative question_count", map[string]any{"quiz_id": "q1", "question_count": -2}, http.StatusBadRequest},
+		{"unknown elimination", map[string]any{"quiz_id": "q1", "elimination": "sudden"}, http.StatusBadRequest},
+		{"elimination_count zero", map[string]any{"quiz_id": "q1", "elimination": "bottom", "elimination_count": 0}, http.StatusBadRequest},
+		{"round without title", map[string]any{"quiz_id": "q1", "rounds": []any{map[string]any{"title": " "}}}, http.StatusBadRequest},
+		{"round with unknown scoring", map[string]any{"quiz_id": "q1", "rounds": []any{map[string]any{"title": "R1", "scoring_strategy": "bonus"}}}, http.StatusBadRequest},
+		{"round multiplier too high", map[string]any{"quiz_id": "q1", "rounds": []any{map[string]any{"title": "R1", "points_multiplier": 3}}}, http.StatusBadRequest},
+		{"rounds in self_paced", map[string]any{"quiz_id": "q1", "mode": "self_paced", "closes_at": "2099-01-01T00:00:00Z", "rounds": []any{map[string]any{"title": "R1"}}}, http.StatusBadRequest},
 	}
 
 	for _, tc := range tests {
@@ -80,3 +102,53 @@ func TestGenerateCode(t *testing.T) {
 		t.Errorf("too many collisions: only %d unique codes in 100 attempts", len(codes))
 	}
 }
+
+func TestValidateElimination(t *testing.T) {
+	if _, _, msg := validateElimination("wrong_answer", nil, models.SessionModeSelfPaced); msg == "" {
+		t.Error("elimination should be rejected for self-paced sessions")
+	}
+	mode, n, msg := validateElimination("", nil, models.SessionModeLive)
+	if msg != "" || mode != models.EliminationOff || n != 1 {
+		t.Errorf("defaults = %q, %d, %q", mode, n, msg)
+	}
+	three := 3
+	mode, n, msg = validateElimination("bottom", &three, models.SessionModeLive)
+	if msg != "" || mode != models.EliminationBottom || n != 3 {
+		t.Errorf("bottom = %q, %d, %q", mode, n, msg)
+	}
+}
+
+func TestValidateLives(t *testing.T) {
+	if n, msg := validateLives(models.EliminationOff, nil); msg != "" || n != defaultLives {
+		t.Errorf("defaults = %d, %q", n, msg)
+	}
+	five := 5
+	if n, msg := validateLives(models.EliminationLives, &five); msg != "" || n != 5 {
+		t.Errorf("lives = %d, %q", n, msg)
+	}
+	if _, msg := validateLives(models.EliminationWrongAnswer, &five); msg == "" {
+		t.Error("lives should be rejected outside lives mode")
+	}
+	tooMany := maxLives + 1
+	if _, msg := validateLives(models.EliminationLives, &tooMany); msg == "" {
+		t.Error("lives above the maximum should be rejected")
+	}
+}
+
+func TestValidateRounds(t *testing.T) {
+	rounds := []models.SessionRound{{Title: "  Seerah "}, {Title: "Fiqh"}}
+	if msg := validateRounds(rounds, models.SessionModeLive); msg != "" {
+		t.Fatalf("unexpected error: %s", msg)
+	}
+	if rounds[0].Title != "Seerah" || rounds[1].Position != 1 {
+		t.Errorf("rounds = %+v, want trimmed titles and positions numbered", rounds)
+	}
+
+	tooMany := make([]models.SessionRound, maxRounds+1)
+	for i := range tooMany {
+		tooMany[i].Title = "R"
+	}
+	if msg := validateRounds(tooMany, models.SessionModeLive); msg == "" {
+		t.Error("expected an error for too many rounds")
+	}
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/HassanA01/Iftarootv2/backend/internal/hub"
+	"github.com/HassanA01/Iftarootv2/backend/internal/models"
+)
+
+const (
+	minTeams          = 2
+	maxTeams          = 10
+	maxTeamNameLength = 40
+)
+
+// errTeamNotFound is returned when a player picks a team outside their session.
+var errTeamNotFound = errors.New("team not found")
+
+// validateTeams resolves team settings for a new session, defaulting to no
+// teams and sum scoring. Team names are trimmed in place. Returns a
+// client-facing message if the settings are invalid.
+func validateTeams(assignment, scoring string, teams []string) (models.TeamAssignment, models.TeamScoring, string) {
+	a := models.TeamAssignment(assignment)
+	switch a {
+	case "":
+		a = models.TeamAssignmentNone
+	case models.TeamAssignmentNone, models.TeamAssignmentChoose, models.TeamAssignmentAuto:
+	default:
+		return "", "", "team_assignment must be none, choose or auto"
+	}
+	sc := models.TeamScoring(scoring)
+	switch sc {
+	case "":
+		sc = models.TeamScoringSum
+	case models.TeamScoringSum, models.TeamScoringAverage, models.TeamScoringBest:
+	default:
+		return "", "", "team_scoring must be sum, average or best"
+	}
+
+	if a == models.TeamAssignmentNone {
+		if len(teams) > 0 {
+			return "", "", "teams require a team_assignment"
+		}
+		return a, sc, ""
+	}
+	if len(teams) < minTeams || len(teams) > maxTeams {
+		return "", "", fmt.Sprintf("between %d and %d teams are required", minTeams, maxTeams)
+	}
+	seen := make(map[string]bool, len(teams))
+	for i, name := range teams {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return "", "", "team names cannot be empty"
+		}
+		if len([]rune(name)) > maxTeamNameLength {
+			return "", "", fmt.Sprintf("team names must be at most %d characters", maxTeamNameLength)
+		}
+		key := strings.ToLower(name)
+		if seen[key] {
+			return "", "", "team names must be unique"
+		}
+		seen[key] = true
+		teams[i] = name
+	}
+	return a, sc, ""
+}
+
+// insertTeams creates a session's teams within tx.
+func insertTeams(ctx context.Context, tx pgx.Tx, sessionID uuid.UUID, teams []string) error {
+	for _, name := range teams {
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO game_teams (id, session_id, name) VALUES ($1, $2, $3)`,
+			uuid.New(), sessionID, name,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadTeams returns a session's teams in creation order.
+func (h *Handler) loadTeams(ctx context.Context, sessionID uuid.UUID) ([]models.GameTeam, error) {
+	rows, err := h.db.Query(ctx,
+		`SELECT id, session_id, name, created_at FROM game_teams WHERE session_id = $1 ORDER BY created_at, name`,
+		sessionID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var teams []models.GameTeam
+	for rows.Next() {
+		var t models.GameTeam
+		if err := rows.Scan(&t.ID, &t.SessionID, &t.Name, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		teams = append(teams, t)
+	}
+	return teams, rows.Err()
+}
+
+// assignTeam picks the team for a joining player: the requested team when
+// players may choose, otherwise the team with the fewest players.
+func (h *Handler) assignTeam(ctx context.Context, session models.GameSession, requested string) (*uuid.UUID, error) {
+	switch session.TeamAssignment {
+	case models.TeamAssignmentChoose:
+		if requested != "" {
+			return h.sessionTeam(ctx, session.ID, requested)
+		}
+	case models.TeamAssignmentAuto:
+	default:
+		return nil, nil
+	}
+
+	var teamID uuid.UUID
+	err := h.db.QueryRow(ctx,
+		`SELECT t.id FROM game_teams t
+		 LEFT JOIN game_players p ON p.team_id = t.id
+		 WHERE t.session_id = $1
+		 GROUP BY t.id
+		 ORDER BY COUNT(p.id), t.created_at, t.name
+		 LIMIT 1`,
+		session.ID,
+	).Scan(&teamID)
+	if err != nil {
+		return nil, err
+	}
+	return &teamID, nil
+}
+
+// sessionTeam checks that teamID belongs to the session.
+func (h *Handler) sessionTeam(ctx context.Context, sessionID uuid.UUID, teamID string) (*uuid.UUID, error) {
+	var id uuid.UUID
+	err := h.db.QueryRow(ctx,
+		`SELECT id FROM game_teams WHERE id::text = $1 AND session_id = $2`, teamID, sessionID,
+	).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errTeamNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// chooseTeam moves a player to another team while the session is still in
+// its lobby, and tells the room.
+func (h *Handler) chooseTeam(ctx context.Context, sessionCode, playerID, teamID string) error {
+	var session models.GameSession
+	err := h.db.QueryRow(ctx,
+		`SELECT `+sessionColumns+` FROM game_sessions WHERE code = $1`, sessionCode,
+	).Scan(sessionScanDest(&session)...)
+	if err != nil {
+		return err
+	}
+	if session.Status != models.GameStatusWaiting || session.TeamAssignment != models.TeamAssignmentChoose {
+		return fmt.Errorf("teams cannot be chosen in this session")
+	}
+	team, err := h.sessionTeam(ctx, session.ID, teamID)
+	if err != nil {
+		return err
+	}
+	tag, err := h.db.Exec(ctx,
+		`UPDATE game_players SET team_id = $1 WHERE id::text = $2 AND session_id = $3`,
+		team, playerID, session.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("player not in session")
+	}
+
+	h.hub.Broadcast(sessionCode, hub.Message{
+		Type: hub.MsgTeamChanged,
+		Payload: map[string]string{
+			"player_id": playerID,
+			"team_id":   team.String(),
+		},
+	})
+	return nil
+}
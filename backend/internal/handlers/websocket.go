@@ -29,7 +29,7 @@ const (
 	writeWait      = 10 * time.Second
 	pongWait       = 60 * time.Second
 	pingPeriod     = (pongWait * 9) / 10
-	maxMessageSize = 512
+	maxMessageSize = 2048
 )
 
 func (h *Handler) HostWebSocket(w http.ResponseWriter, r *http.Request) {
@@ -188,28 +188,168 @@ func handleMessage(h *Handler, client *hub.Client, sessionCode string, isHost bo
 		if !ok {
 			return
 		}
-		questionID, _ := payload["question_id"].(string)
-		optionID, _ := payload["option_id"].(string)
-		if questionID == "" || optionID == "" {
+		sub := game.Submission{
+			OptionIDs: stringSlice(payload["option_ids"]),
+		}
+		sub.QuestionID, _ = payload["question_id"].(string)
+		sub.OptionID, _ = payload["option_id"].(string)
+		sub.Text, _ = payload["text"].(string)
+		if n, ok := payload["number"].(float64); ok {
+			sub.Number = &n
+		}
+		if sub.QuestionID == "" {
 			return
 		}
-		if err := h.engine.SubmitAnswer(ctx, sessionCode, client.ID, questionID, optionID); err != nil {
+		if err := h.engine.SubmitAnswer(ctx, sessionCode, client.ID, sub); err != nil {
 			log.Printf("engine.SubmitAnswer error: %v", err)
 		}
 
 	case hub.MsgNextQuestion:
 		if !isHost {
+			// In self-paced sessions players move themselves on.
+			if err := h.engine.AdvanceSelfPaced(ctx, sessionCode, client.ID); err != nil {
+				log.Printf("engine.AdvanceSelfPaced error: %v", err)
+			}
 			return
 		}
 		if err := h.engine.NextQuestion(ctx, sessionCode); err != nil {
 			log.Printf("engine.NextQuestion error: %v", err)
 		}
 
+	case hub.MsgStartRound:
+		if !isHost {
+			return
+		}
+		if err := h.engine.StartNextRound(ctx, sessionCode); err != nil {
+			log.Printf("engine.StartNextRound error: %v", err)
+		}
+
+	case hub.MsgBuzz:
+		if isHost {
+			return
+		}
+		if err := h.engine.Buzz(ctx, sessionCode, client.ID); err != nil {
+			log.Printf("engine.Buzz error: %v", err)
+		}
+
+	case hub.MsgJudgeAnswer:
+		if !isHost {
+			return
+		}
+		payload, ok := msg.Payload.(map[string]any)
+		if !ok {
+			return
+		}
+		correct, ok := payload["correct"].(bool)
+		if !ok {
+			return
+		}
+		if err := h.engine.JudgeAnswer(ctx, sessionCode, correct); err != nil {
+			log.Printf("engine.JudgeAnswer error: %v", err)
+		}
+
+	case hub.MsgWager:
+		if isHost {
+			return
+		}
+		payload, ok := msg.Payload.(map[string]any)
+		if !ok {
+			return
+		}
+		amount, ok := payload["amount"].(float64)
+		if !ok {
+			return
+		}
+		if err := h.engine.SubmitWager(ctx, sessionCode, client.ID, int(amount)); err != nil {
+			log.Printf("engine.SubmitWager error: %v", err)
+		}
+
+	case hub.MsgChooseTeam:
+		if isHost {
+			return
+		}
+		payload, ok := msg.Payload.(map[string]any)
+		if !ok {
+			return
+		}
+		teamID, _ := payload["team_id"].(string)
+		if err := h.chooseTeam(ctx, sessionCode, client.ID, teamID); err != nil {
+			log.Printf("chooseTeam error: %v", err)
+		}
+
+	case hub.MsgPauseQuestion:
+		if !isHost {
+			return
+		}
+		if err := h.engine.PauseQuestion(ctx, sessionCode); err != nil {
+			log.Printf("engine.PauseQuestion error: %v", err)
+		}
+
+	case hub.MsgResumeQuestion:
+		if !isHost {
+			return
+		}
+		if err := h.engine.ResumeQuestion(ctx, sessionCode); err != nil {
+			log.Printf("engine.ResumeQuestion error: %v", err)
+		}
+
+	case hub.MsgExtendTime:
+		if !isHost {
+			return
+		}
+		payload, ok := msg.Payload.(map[string]any)
+		if !ok {
+			return
+		}
+		seconds, _ := payload["seconds"].(float64)
+		if err := h.engine.ExtendQuestion(ctx, sessionCode, int(seconds)); err != nil {
+			log.Printf("engine.ExtendQuestion error: %v", err)
+		}
+
+	case hub.MsgEndQuestion:
+		if !isHost {
+			return
+		}
+		if err := h.engine.EndQuestionNow(ctx, sessionCode); err != nil {
+			log.Printf("engine.EndQuestionNow error: %v", err)
+		}
+
+	case hub.MsgSkipQuestion:
+		if !isHost {
+			return
+		}
+		if err := h.engine.SkipQuestion(ctx, sessionCode); err != nil {
+			log.Printf("engine.SkipQuestion error: %v", err)
+		}
+
+	case hub.MsgRerunQuestion:
+		if !isHost {
+			return
+		}
+		if err := h.engine.RerunQuestion(ctx, sessionCode); err != nil {
+			log.Printf("engine.RerunQuestion error: %v", err)
+		}
+
 	default:
 		log.Printf("unhandled message type: %s from isHost=%v", msg.Type, isHost)
 	}
 }
 
+// stringSlice converts a decoded JSON array into a []string, skipping non-string elements.
+func stringSlice(v any) []string {
+	items, ok := v.([]any)
+	if !ok {
+		return nil
+	}
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
 // sendInitialState sends the current game state to a newly connected client.
 func (h *Handler) sendInitialState(ctx context.Context, sessionCode string, client *hub.Client, isHost bool) {
 	// Small delay to ensure writePump goroutine is running.
@@ -222,22 +362,58 @@ func (h *Handler) sendInitialState(ctx context.Context, sessionCode string, clie
 
 	var msg *hub.Message
 	switch state.Phase {
-	case game.PhaseQuestion:
+	case game.PhaseQuestion, game.PhaseBuzzerFloor, game.PhaseBuzzerJudge:
 		if isHost {
 			msg, _ = h.engine.GetHostQuestion(ctx, sessionCode)
 		} else {
-			msg, _ = h.engine.GetCurrentQuestion(ctx, sessionCode)
+			msg, _ = h.engine.GetCurrentQuestion(ctx, sessionCode, client.ID)
+		}
+	case game.PhaseWager:
+		msg, _ = h.engine.WagerMessage(ctx, sessionCode, client.ID, isHost)
+	case game.PhaseIntermission:
+		msg, _ = h.engine.IntermissionMessage(ctx, sessionCode)
+	case game.PhaseSuddenDeath:
+		msg, _ = h.engine.SuddenDeathMessage(ctx, sessionCode, isHost)
+	case game.PhaseSelfPaced:
+		if isHost {
+			msg, _ = h.engine.SelfPacedProgress(ctx, sessionCode)
+		} else {
+			msg, _ = h.engine.SelfPacedQuestion(ctx, sessionCode, client.ID)
 		}
 	}
 
-	if msg != nil {
-		data, err := json.Marshal(msg)
-		if err != nil {
-			return
-		}
-		select {
-		case client.Send <- data:
-		default:
-		}
+	if !isHost && state.IsEliminated(client.ID) {
+		// Rejoining spectators need to know they are out before anything else.
+		sendToClient(client, &hub.Message{
+			Type:    hub.MsgEliminated,
+			Payload: map[string]any{"question_index": state.Eliminated[client.ID]},
+		})
+	}
+
+	if msg == nil {
+		return
+	}
+	sendToClient(client, msg)
+
+	// Follow the question with the live clock so a rejoining client sees
+	// pauses and extensions rather than the full time limit.
+	if timer, _ := h.engine.TimerMessage(ctx, sessionCode); timer != nil {
+		sendToClient(client, timer)
+	}
+	// And with who holds the buzzer, if anyone.
+	if buzz, _ := h.engine.BuzzerMessage(ctx, sessionCode, client.ID, isHost); buzz != nil {
+		sendToClient(client, buzz)
+	}
+}
+
+// sendToClient queues msg on a single client's send channel, dropping it if full.
+func sendToClient(client *hub.Client, msg *hub.Message) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	select {
+	case client.Send <- data:
+	default:
 	}
 }
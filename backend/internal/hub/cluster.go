@@ -0,0 +1,276 @@
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// roomChannelPattern matches the pub/sub channel of every room.
+	roomChannelPattern = "hub:room:*"
+	// nodeTTL is how long a node counts as alive after its last heartbeat.
+	nodeTTL = 30 * time.Second
+	// nodeHeartbeat is how often a running node renews its liveness.
+	nodeHeartbeat = 10 * time.Second
+	// presenceTTL bounds how long a room's presence outlives its last join.
+	presenceTTL = 24 * time.Hour
+	// minSubscribeBackoff and maxSubscribeBackoff bound the wait between
+	// attempts to subscribe to room messages again.
+	minSubscribeBackoff = 100 * time.Millisecond
+	maxSubscribeBackoff = 5 * time.Second
+)
+
+// roomChannel returns the pub/sub channel messages for a room are published on.
+func roomChannel(code string) string { return fmt.Sprintf("hub:room:%s", code) }
+
+// presenceKey returns the Redis key of a room's presence hash. Each field is
+// a node and player ID, and holds how many connections that player has open
+// to the room on that node.
+func presenceKey(code string) string { return fmt.Sprintf("hub:presence:%s", code) }
+
+// nodeKey returns the Redis key that exists while a node is alive.
+func nodeKey(nodeID string) string { return fmt.Sprintf("hub:node:%s", nodeID) }
+
+func presenceField(nodeID, clientID string) string { return nodeID + "/" + clientID }
+
+// target selects which of a room's clients receive a message.
+type target string
+
+const (
+	targetAll     target = "all"
+	targetHost    target = "host"
+	targetPlayers target = "players"
+	targetPlayer  target = "player"
+)
+
+// matches reports whether c receives a message sent to t; clientID names the
+// player for targetPlayer.
+func (t target) matches(c *Client, clientID string) bool {
+	switch t {
+	case targetHost:
+		return c.IsHost
+	case targetPlayers:
+		return !c.IsHost
+	case targetPlayer:
+		return c.ID == clientID
+	default:
+		return true
+	}
+}
+
+// envelope is a room message as published between nodes.
+type envelope struct {
+	Room     string          `json:"room"`
+	Target   target          `json:"target"`
+	ClientID string          `json:"client_id,omitempty"`
+	Data     json.RawMessage `json:"data"`
+}
+
+func (h *Hub) publish(roomCode string, to target, clientID string, data []byte) error {
+	env, err := json.Marshal(envelope{Room: roomCode, Target: to, ClientID: clientID, Data: data})
+	if err != nil {
+		return err
+	}
+	return h.redis.Publish(context.Background(), roomChannel(roomCode), env).Err()
+}
+
+// Run delivers room messages published by any node to this node's clients
+// and keeps the node alive in the cluster's presence, until ctx is done.
+func (h *Hub) Run(ctx context.Context) {
+	if h.redis == nil {
+		log.Println("hub running (single node)")
+		return
+	}
+
+	sub := h.redis.PSubscribe(ctx, roomChannelPattern)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.receive(ctx, sub)
+	}()
+
+	h.heartbeat(ctx)
+	ticker := time.NewTicker(nodeHeartbeat)
+	defer ticker.Stop()
+
+	log.Printf("hub running as node %s", h.nodeID)
+	for {
+		select {
+		case <-ctx.Done():
+			_ = sub.Close()
+			<-done
+			h.leaveCluster()
+			return
+		case <-ticker.C:
+			h.heartbeat(ctx)
+		}
+	}
+}
+
+// receive delivers messages from sub until it is closed. While the
+// subscription is down, send delivers to this node's clients directly, and
+// receive keeps trying to subscribe again, backing off up to
+// maxSubscribeBackoff. A quiet subscription is pinged every nodeHeartbeat to
+// find out whether it is still there.
+func (h *Hub) receive(ctx context.Context, sub *redis.PubSub) {
+	defer h.live.Store(false)
+	backoff := minSubscribeBackoff
+	for {
+		msg, err := sub.ReceiveTimeout(ctx, nodeHeartbeat)
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			err = sub.Ping(ctx)
+		}
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, redis.ErrClosed) {
+				return
+			}
+			if h.live.Swap(false) {
+				log.Printf("hub subscription lost, delivering locally: %v", err)
+			} else {
+				log.Printf("hub subscribe error, retrying in %s: %v", backoff, err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, maxSubscribeBackoff)
+			continue
+		}
+		backoff = minSubscribeBackoff
+
+		switch m := msg.(type) {
+		case *redis.Subscription:
+			if !h.live.Swap(true) {
+				log.Printf("hub subscribed to %s", m.Channel)
+			}
+			h.subscribeOnce.Do(func() { close(h.subscribed) })
+		case *redis.Message:
+			var env envelope
+			if err := json.Unmarshal([]byte(m.Payload), &env); err != nil {
+				log.Printf("hub message error: %v", err)
+				continue
+			}
+			h.deliver(env.Room, env.Target, env.ClientID, env.Data)
+		}
+	}
+}
+
+func (h *Hub) heartbeat(ctx context.Context) {
+	if err := h.redis.Set(ctx, nodeKey(h.nodeID), time.Now().Unix(), nodeTTL).Err(); err != nil {
+		log.Printf("hub heartbeat error: %v", err)
+	}
+}
+
+// leaveCluster removes this node and its connections from the presence
+// other nodes see.
+func (h *Hub) leaveCluster() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	pipe := h.redis.Pipeline()
+	pipe.Del(ctx, nodeKey(h.nodeID))
+	for code, room := range h.rooms {
+		for c := range room {
+			if !c.IsHost {
+				pipe.HDel(ctx, presenceKey(code), presenceField(h.nodeID, c.ID))
+			}
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("hub leave error: %v", err)
+	}
+}
+
+// syncPresence records how many connections clientID has open to a room on
+// this node. It must be called without h.mu held, after the room changed.
+// The count is read under h.presenceMu just before it is written, so the last
+// write for a player always reflects their latest connections.
+func (h *Hub) syncPresence(roomCode, clientID string) {
+	if h.redis == nil {
+		return
+	}
+	h.presenceMu.Lock()
+	defer h.presenceMu.Unlock()
+	h.mu.RLock()
+	n := h.connections(roomCode, clientID)
+	h.mu.RUnlock()
+
+	ctx := context.Background()
+	key, field := presenceKey(roomCode), presenceField(h.nodeID, clientID)
+	var err error
+	if n > 0 {
+		pipe := h.redis.TxPipeline()
+		pipe.HSet(ctx, key, field, n)
+		pipe.Expire(ctx, key, presenceTTL)
+		_, err = pipe.Exec(ctx)
+	} else {
+		err = h.redis.HDel(ctx, key, field).Err()
+	}
+	if err != nil {
+		log.Printf("hub presence error: %v", err)
+	}
+}
+
+// clusterPlayers returns the number of connections each player has open to a
+// room across all live nodes. Connections left behind by nodes that stopped
+// without leaving are cleared.
+func (h *Hub) clusterPlayers(roomCode string) (map[string]int, error) {
+	ctx := context.Background()
+	fields, err := h.redis.HGetAll(ctx, presenceKey(roomCode)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	alive := map[string]bool{h.nodeID: true}
+	var others []string
+	for field := range fields {
+		nodeID, _, _ := strings.Cut(field, "/")
+		if _, seen := alive[nodeID]; !seen {
+			alive[nodeID] = false
+			others = append(others, nodeID)
+		}
+	}
+	if len(others) > 0 {
+		keys := make([]string, len(others))
+		for i, id := range others {
+			keys[i] = nodeKey(id)
+		}
+		vals, err := h.redis.MGet(ctx, keys...).Result()
+		if err != nil {
+			return nil, err
+		}
+		for i, v := range vals {
+			alive[others[i]] = v != nil
+		}
+	}
+
+	counts := make(map[string]int)
+	var stale []string
+	for field, v := range fields {
+		nodeID, clientID, _ := strings.Cut(field, "/")
+		if !alive[nodeID] {
+			stale = append(stale, field)
+			continue
+		}
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			counts[clientID] += n
+		}
+	}
+	if len(stale) > 0 {
+		h.redis.HDel(ctx, presenceKey(roomCode), stale...)
+	}
+	return counts, nil
+}
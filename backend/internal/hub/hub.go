@@ -4,8 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"log"
+	"sort"
 	"sync"
+	"sync/atomic"
 
+	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -13,18 +16,48 @@ import (
 type MessageType string
 
 const (
-	MsgPlayerJoined    MessageType = "player_joined"
-	MsgPlayerLeft      MessageType = "player_left"
-	MsgGameStarted     MessageType = "game_started"
-	MsgQuestion        MessageType = "question"
-	MsgAnswerSubmitted MessageType = "answer_submitted"
-	MsgAnswerReveal    MessageType = "answer_reveal"
-	MsgLeaderboard     MessageType = "leaderboard"
-	MsgNextQuestion    MessageType = "next_question"
-	MsgGameOver        MessageType = "game_over"
-	MsgPodium          MessageType = "podium"
-	MsgError           MessageType = "error"
-	MsgPing            MessageType = "ping"
+	MsgPlayerJoined      MessageType = "player_joined"
+	MsgPlayerLeft        MessageType = "player_left"
+	MsgGameStarted       MessageType = "game_started"
+	MsgQuestion          MessageType = "question"
+	MsgAnswerSubmitted   MessageType = "answer_submitted"
+	MsgAnswerReveal      MessageType = "answer_reveal"
+	MsgLeaderboard       MessageType = "leaderboard"
+	MsgNextQuestion      MessageType = "next_question"
+	MsgGameOver          MessageType = "game_over"
+	MsgPodium            MessageType = "podium"
+	MsgPollTally         MessageType = "poll_tally"
+	MsgPauseQuestion     MessageType = "pause_question"
+	MsgResumeQuestion    MessageType = "resume_question"
+	MsgExtendTime        MessageType = "extend_time"
+	MsgTimerUpdate       MessageType = "timer_update"
+	MsgEndQuestion       MessageType = "end_question"
+	MsgSkipQuestion      MessageType = "skip_question"
+	MsgRerunQuestion     MessageType = "rerun_question"
+	MsgQuestionSkipped   MessageType = "question_skipped"
+	MsgQuestionRestarted MessageType = "question_restarted"
+	MsgProgress          MessageType = "progress"
+	MsgSelfPacedFinished MessageType = "self_paced_finished"
+	MsgChooseTeam        MessageType = "choose_team"
+	MsgTeamChanged       MessageType = "team_changed"
+	MsgEliminated        MessageType = "eliminated"
+	MsgWagerOpen         MessageType = "wager_open"
+	MsgWager             MessageType = "wager"
+	MsgWagerPlaced       MessageType = "wager_placed"
+	MsgBuzz              MessageType = "buzz"
+	MsgBuzzed            MessageType = "buzzed"
+	MsgBuzzerFloor       MessageType = "buzzer_floor"
+	MsgBuzzerAnswer      MessageType = "buzzer_answer"
+	MsgJudgeAnswer       MessageType = "judge_answer"
+	MsgBuzzerJudged      MessageType = "buzzer_judged"
+	MsgBuzzerReopened    MessageType = "buzzer_reopened"
+	MsgIntermission      MessageType = "intermission"
+	MsgStartRound        MessageType = "start_round"
+	MsgRoundStarted      MessageType = "round_started"
+	MsgSuddenDeath       MessageType = "sudden_death"
+	MsgSuddenDeathResult MessageType = "sudden_death_result"
+	MsgError             MessageType = "error"
+	MsgPing              MessageType = "ping"
 )
 
 // Message is the envelope for all WebSocket communication.
@@ -41,129 +74,198 @@ type Client struct {
 	Send      chan []byte
 }
 
-// Hub maintains active game rooms and broadcasts messages.
+// Hub maintains the game rooms connected to this node. Messages for a room
+// are published through Redis so that clients connected to any node receive
+// them; without Redis the hub serves a single node.
 type Hub struct {
-	mu    sync.RWMutex
-	rooms map[string]map[*Client]bool // sessionCode -> clients
-	redis *redis.Client
+	mu            sync.RWMutex
+	rooms         map[string]map[*Client]bool // sessionCode -> clients on this node
+	presenceMu    sync.Mutex                  // serializes presence writes to Redis
+	redis         *redis.Client
+	nodeID        string
+	live          atomic.Bool   // set while Run is subscribed to room messages
+	subscribed    chan struct{} // closed once Run first subscribes
+	subscribeOnce sync.Once
 }
 
 func New(redisClient *redis.Client) *Hub {
 	return &Hub{
-		rooms: make(map[string]map[*Client]bool),
-		redis: redisClient,
+		rooms:      make(map[string]map[*Client]bool),
+		redis:      redisClient,
+		nodeID:     uuid.NewString(),
+		subscribed: make(chan struct{}),
 	}
 }
 
-func (h *Hub) Run() {
-	log.Println("hub running")
-}
-
 // JoinRoom adds a client to a room.
 func (h *Hub) JoinRoom(roomCode string, client *Client) {
 	h.mu.Lock()
-	defer h.mu.Unlock()
 	if h.rooms[roomCode] == nil {
 		h.rooms[roomCode] = make(map[*Client]bool)
 	}
 	h.rooms[roomCode][client] = true
+	h.mu.Unlock()
+	if !client.IsHost {
+		h.syncPresence(roomCode, client.ID)
+	}
 }
 
 // LeaveRoom removes a client from a room.
 func (h *Hub) LeaveRoom(roomCode string, client *Client) {
+	h.removeClient(roomCode, client)
+	if !client.IsHost {
+		h.syncPresence(roomCode, client.ID)
+	}
+}
+
+// removeClient removes client from a room, reporting whether it was there.
+func (h *Hub) removeClient(roomCode string, client *Client) bool {
 	h.mu.Lock()
 	defer h.mu.Unlock()
-	if room, ok := h.rooms[roomCode]; ok {
-		delete(room, client)
-		if len(room) == 0 {
-			delete(h.rooms, roomCode)
-		}
+	room, ok := h.rooms[roomCode]
+	if !ok || !room[client] {
+		return false
+	}
+	delete(room, client)
+	if len(room) == 0 {
+		delete(h.rooms, roomCode)
 	}
+	return true
 }
 
 // Broadcast sends a message to all clients in a room.
 func (h *Hub) Broadcast(roomCode string, msg Message) {
+	h.send(roomCode, targetAll, "", msg)
+}
+
+// BroadcastToPlayer sends a message to a specific player by client ID, on
+// every connection they have open.
+func (h *Hub) BroadcastToPlayer(roomCode, clientID string, msg Message) {
+	h.send(roomCode, targetPlayer, clientID, msg)
+}
+
+// BroadcastToHost sends a message only to the host of a room.
+func (h *Hub) BroadcastToHost(roomCode string, msg Message) {
+	h.send(roomCode, targetHost, "", msg)
+}
+
+// BroadcastToPlayers sends a message to all non-host clients in a room.
+func (h *Hub) BroadcastToPlayers(roomCode string, msg Message) {
+	h.send(roomCode, targetPlayers, "", msg)
+}
+
+// send publishes msg for every node to deliver to its clients in the room.
+// Without Redis, while this node is not subscribed to room messages, or if
+// publishing fails, it goes to this node's clients only.
+func (h *Hub) send(roomCode string, to target, clientID string, msg Message) {
 	data, err := json.Marshal(msg)
 	if err != nil {
 		log.Printf("broadcast marshal error: %v", err)
 		return
 	}
+	if h.redis != nil && h.live.Load() {
+		err := h.publish(roomCode, to, clientID, data)
+		if err == nil {
+			return
+		}
+		log.Printf("hub publish error: %v", err)
+	}
+	h.deliver(roomCode, to, clientID, data)
+}
+
+// deliver sends data to this node's clients in a room that match to. A
+// client too slow to keep up with room-wide broadcasts is dropped.
+func (h *Hub) deliver(roomCode string, to target, clientID string, data []byte) {
+	var dropped []*Client
 	h.mu.RLock()
-	defer h.mu.RUnlock()
 	for client := range h.rooms[roomCode] {
+		if !to.matches(client, clientID) {
+			continue
+		}
 		select {
 		case client.Send <- data:
 		default:
-			close(client.Send)
-			delete(h.rooms[roomCode], client)
+			if to == targetAll {
+				dropped = append(dropped, client)
+			}
 		}
 	}
-}
+	h.mu.RUnlock()
 
-// BroadcastToPlayer sends a message to a specific player by client ID.
-func (h *Hub) BroadcastToPlayer(roomCode, clientID string, msg Message) {
-	data, err := json.Marshal(msg)
-	if err != nil {
-		return
-	}
-	h.mu.RLock()
-	defer h.mu.RUnlock()
-	for client := range h.rooms[roomCode] {
-		if client.ID == clientID {
-			select {
-			case client.Send <- data:
-			default:
-			}
-			break
+	for _, client := range dropped {
+		if !h.removeClient(roomCode, client) {
+			continue // already dropped or left
+		}
+		close(client.Send)
+		if !client.IsHost {
+			h.syncPresence(roomCode, client.ID)
 		}
 	}
 }
 
-// BroadcastToHost sends a message only to the host of a room.
-func (h *Hub) BroadcastToHost(roomCode string, msg Message) {
-	data, err := json.Marshal(msg)
+// RoomPlayerIDs returns the distinct IDs of non-host clients in a room,
+// across all nodes.
+func (h *Hub) RoomPlayerIDs(roomCode string) []string {
+	if h.redis == nil {
+		return h.localPlayerIDs(roomCode)
+	}
+	counts, err := h.clusterPlayers(roomCode)
 	if err != nil {
-		log.Printf("broadcast marshal error: %v", err)
-		return
+		log.Printf("hub presence error: %v", err)
+		return h.localPlayerIDs(roomCode)
 	}
-	h.mu.RLock()
-	defer h.mu.RUnlock()
-	for client := range h.rooms[roomCode] {
-		if client.IsHost {
-			select {
-			case client.Send <- data:
-			default:
-			}
-		}
+	ids := make([]string, 0, len(counts))
+	for id := range counts {
+		ids = append(ids, id)
 	}
+	sort.Strings(ids)
+	return ids
 }
 
-// BroadcastToPlayers sends a message to all non-host clients in a room.
-func (h *Hub) BroadcastToPlayers(roomCode string, msg Message) {
-	data, err := json.Marshal(msg)
+// RoomPlayerCount returns the number of non-host clients in a room, across
+// all nodes.
+func (h *Hub) RoomPlayerCount(roomCode string) int {
+	if h.redis == nil {
+		return h.localPlayerCount(roomCode)
+	}
+	counts, err := h.clusterPlayers(roomCode)
 	if err != nil {
-		log.Printf("broadcast marshal error: %v", err)
-		return
+		log.Printf("hub presence error: %v", err)
+		return h.localPlayerCount(roomCode)
 	}
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	return total
+}
+
+func (h *Hub) localPlayerIDs(roomCode string) []string {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
-	for client := range h.rooms[roomCode] {
-		if !client.IsHost {
-			select {
-			case client.Send <- data:
-			default:
-			}
+	seen := make(map[string]bool)
+	var ids []string
+	for c := range h.rooms[roomCode] {
+		if !c.IsHost && !seen[c.ID] {
+			seen[c.ID] = true
+			ids = append(ids, c.ID)
 		}
 	}
+	return ids
 }
 
-// RoomPlayerCount returns the number of non-host clients in a room.
-func (h *Hub) RoomPlayerCount(roomCode string) int {
+func (h *Hub) localPlayerCount(roomCode string) int {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
+	return h.connections(roomCode, "")
+}
+
+// connections counts the non-host clients in a room on this node, only those
+// with clientID if it is set. The caller holds h.mu.
+func (h *Hub) connections(roomCode, clientID string) int {
 	count := 0
 	for c := range h.rooms[roomCode] {
-		if !c.IsHost {
+		if !c.IsHost && (clientID == "" || c.ID == clientID) {
 			count++
 		}
 	}
@@ -1,6 +1,15 @@
 package hub
 
-import "testing"
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
 
 func newTestHub() *Hub {
 	return New(nil) // nil redis is fine for non-Redis methods
@@ -75,3 +84,195 @@ func TestBroadcastToPlayersNoPlayers(t *testing.T) {
 		t.Errorf("host should not receive player broadcast, got %d", len(hostSend))
 	}
 }
+
+func TestRoomPlayerIDs(t *testing.T) {
+	h := newTestHub()
+
+	h.JoinRoom("ROOM5", &Client{ID: "host-1", IsHost: true, Send: make(chan []byte, 1)})
+	h.JoinRoom("ROOM5", &Client{ID: "player-1", Send: make(chan []byte, 1)})
+	// Same player connected from a second tab.
+	h.JoinRoom("ROOM5", &Client{ID: "player-1", Send: make(chan []byte, 1)})
+	h.JoinRoom("ROOM5", &Client{ID: "player-2", Send: make(chan []byte, 1)})
+
+	ids := h.RoomPlayerIDs("ROOM5")
+	if len(ids) != 2 {
+		t.Errorf("expected 2 distinct players, got %v", ids)
+	}
+	if got := h.RoomPlayerIDs("EMPTY"); len(got) != 0 {
+		t.Errorf("expected no players in empty room, got %v", got)
+	}
+}
+
+// newClusterHubs starts n hubs sharing one Redis, as separate nodes would.
+func newClusterHubs(t *testing.T, n int) []*Hub {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	hubs := make([]*Hub, n)
+	for i := range hubs {
+		rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+		t.Cleanup(func() { _ = rdb.Close() })
+		hubs[i] = New(rdb)
+		go hubs[i].Run(ctx)
+		select {
+		case <-hubs[i].subscribed:
+		case <-time.After(2 * time.Second):
+			t.Fatal("hub did not subscribe")
+		}
+	}
+	return hubs
+}
+
+func receive(t *testing.T, send chan []byte) Message {
+	t.Helper()
+	select {
+	case data := <-send:
+		var msg Message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+		return msg
+	case <-time.After(2 * time.Second):
+		t.Fatal("no message received")
+		return Message{}
+	}
+}
+
+func TestBroadcastAcrossNodes(t *testing.T) {
+	hubs := newClusterHubs(t, 2)
+
+	hostSend := make(chan []byte, 4)
+	p1Send := make(chan []byte, 4)
+	p2Send := make(chan []byte, 4)
+	hubs[0].JoinRoom("ROOM6", &Client{ID: "host-1", IsHost: true, Send: hostSend})
+	hubs[0].JoinRoom("ROOM6", &Client{ID: "player-1", Send: p1Send})
+	hubs[1].JoinRoom("ROOM6", &Client{ID: "player-2", Send: p2Send})
+
+	hubs[1].BroadcastToHost("ROOM6", Message{Type: MsgAnswerSubmitted})
+	if msg := receive(t, hostSend); msg.Type != MsgAnswerSubmitted {
+		t.Errorf("host got %q, want %q", msg.Type, MsgAnswerSubmitted)
+	}
+
+	hubs[0].BroadcastToPlayer("ROOM6", "player-2", Message{Type: MsgEliminated})
+	if msg := receive(t, p2Send); msg.Type != MsgEliminated {
+		t.Errorf("player-2 got %q, want %q", msg.Type, MsgEliminated)
+	}
+
+	hubs[0].Broadcast("ROOM6", Message{Type: MsgGameOver})
+	for _, send := range []chan []byte{hostSend, p1Send, p2Send} {
+		if msg := receive(t, send); msg.Type != MsgGameOver {
+			t.Errorf("got %q, want %q", msg.Type, MsgGameOver)
+		}
+	}
+	if len(p1Send) != 0 {
+		t.Errorf("player-1 should not receive player-2's message, has %d queued", len(p1Send))
+	}
+}
+
+func TestRoomPlayersAcrossNodes(t *testing.T) {
+	hubs := newClusterHubs(t, 2)
+
+	p1 := &Client{ID: "player-1", Send: make(chan []byte, 1)}
+	hubs[0].JoinRoom("ROOM7", &Client{ID: "host-1", IsHost: true, Send: make(chan []byte, 1)})
+	hubs[0].JoinRoom("ROOM7", p1)
+	hubs[1].JoinRoom("ROOM7", &Client{ID: "player-1", Send: make(chan []byte, 1)})
+	hubs[1].JoinRoom("ROOM7", &Client{ID: "player-2", Send: make(chan []byte, 1)})
+
+	for i, h := range hubs {
+		if n := h.RoomPlayerCount("ROOM7"); n != 3 {
+			t.Errorf("node %d: RoomPlayerCount = %d, want 3", i, n)
+		}
+		if ids := h.RoomPlayerIDs("ROOM7"); !slices.Equal(ids, []string{"player-1", "player-2"}) {
+			t.Errorf("node %d: RoomPlayerIDs = %v", i, ids)
+		}
+	}
+
+	hubs[0].LeaveRoom("ROOM7", p1)
+	if n := hubs[1].RoomPlayerCount("ROOM7"); n != 2 {
+		t.Errorf("after leave: RoomPlayerCount = %d, want 2", n)
+	}
+
+	// A node that stops without leaving drops out once its heartbeat lapses.
+	if err := hubs[1].redis.Del(context.Background(), nodeKey(hubs[1].nodeID)).Err(); err != nil {
+		t.Fatal(err)
+	}
+	if n := hubs[0].RoomPlayerCount("ROOM7"); n != 0 {
+		t.Errorf("after node lapsed: RoomPlayerCount = %d, want 0", n)
+	}
+}
+
+func TestSlowClientLeavesPresence(t *testing.T) {
+	hubs := newClusterHubs(t, 2)
+
+	slow := &Client{ID: "player-1", Send: make(chan []byte)} // never read
+	hubs[0].JoinRoom("ROOM8", slow)
+	if n := hubs[1].RoomPlayerCount("ROOM8"); n != 1 {
+		t.Fatalf("RoomPlayerCount = %d, want 1", n)
+	}
+
+	hubs[1].Broadcast("ROOM8", Message{Type: MsgPing})
+	deadline := time.Now().Add(2 * time.Second)
+	for hubs[1].RoomPlayerCount("ROOM8") != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("dropped client still counted in presence")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, open := <-slow.Send; open {
+		t.Error("dropped client's channel should be closed")
+	}
+}
+
+// waitFor polls cond until it holds, failing the test after a few seconds.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestBroadcastWhileUnsubscribed(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	// Messages reach this node's clients before the hub has subscribed.
+	h := New(rdb)
+	send := make(chan []byte, 4)
+	h.JoinRoom("ROOM8", &Client{ID: "player-1", Send: send})
+	h.Broadcast("ROOM8", Message{Type: MsgGameStarted})
+	if msg := receive(t, send); msg.Type != MsgGameStarted {
+		t.Errorf("before subscribing got %q, want %q", msg.Type, MsgGameStarted)
+	}
+
+	go h.Run(ctx)
+	waitFor(t, "subscription", h.live.Load)
+
+	// Losing Redis falls back to local delivery until the hub subscribes again.
+	mr.Close()
+	waitFor(t, "subscription loss", func() bool { return !h.live.Load() })
+	h.Broadcast("ROOM8", Message{Type: MsgAnswerReveal})
+	if msg := receive(t, send); msg.Type != MsgAnswerReveal {
+		t.Errorf("while down got %q, want %q", msg.Type, MsgAnswerReveal)
+	}
+
+	if err := mr.Restart(); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "resubscription", h.live.Load)
+	h.Broadcast("ROOM8", Message{Type: MsgGameOver})
+	if msg := receive(t, send); msg.Type != MsgGameOver {
+		t.Errorf("after resubscribing got %q, want %q", msg.Type, MsgGameOver)
+	}
+	if len(send) != 0 {
+		t.Errorf("%d messages delivered twice", len(send))
+	}
+}
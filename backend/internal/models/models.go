@@ -18,20 +18,105 @@ type Admin struct {
 // Quiz
 
 type Quiz struct {
-	ID        uuid.UUID  `json:"id" db:"id"`
-	AdminID   uuid.UUID  `json:"admin_id" db:"admin_id"`
-	Title     string     `json:"title" db:"title"`
-	CreatedAt time.Time  `json:"created_at" db:"created_at"`
-	Questions []Question `json:"questions,omitempty"`
+	ID              uuid.UUID  `json:"id" db:"id"`
+	AdminID         uuid.UUID  `json:"admin_id" db:"admin_id"`
+	Title           string     `json:"title" db:"title"`
+	ScoringStrategy string     `json:"scoring_strategy" db:"scoring_strategy"`
+	StreakSchedule  []int      `json:"streak_schedule" db:"streak_schedule"` // empty = engine default
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	Questions       []Question `json:"questions,omitempty"`
+
+	ShuffleQuestions bool          `json:"shuffle_questions" db:"shuffle_questions"`
+	ShuffleOptions   OptionShuffle `json:"shuffle_options" db:"shuffle_options"`
+	QuestionCount    *int          `json:"question_count,omitempty" db:"question_count"` // draw this many; nil or 0 = all
+
+	DrawRules []DrawRule `json:"draw_rules,omitempty"` // bank questions added after Questions
+
+	// NegativeScores lets lost wagers and wrong-answer penalties take scores
+	// below zero; otherwise scores are floored at zero.
+	NegativeScores bool `json:"negative_scores" db:"negative_scores"`
+
+	TieBreaker TieBreaker `json:"tie_breaker" db:"tie_breaker"`
 }
 
+// TieBreaker is how players level on points are ranked.
+type TieBreaker string
+
+const (
+	TieBreakerTime        TieBreaker = "time"         // less total answer time, then the earliest correct answer, goes first
+	TieBreakerShared      TieBreaker = "shared"       // level players share a rank
+	TieBreakerSuddenDeath TieBreaker = "sudden_death" // shared, but podium ties play reserve questions
+)
+
+// OptionShuffle is how answer options are reordered for players.
+type OptionShuffle string
+
+const (
+	OptionShuffleNone    OptionShuffle = "none"    // quiz order
+	OptionShuffleSession OptionShuffle = "session" // one random order per session
+	OptionShufflePlayer  OptionShuffle = "player"  // a different order for each player
+)
+
+type QuestionType string
+
+const (
+	QuestionTypeSingleChoice QuestionType = "single_choice" // exactly one correct option
+	QuestionTypeMultiSelect  QuestionType = "multi_select"  // select all that apply
+	QuestionTypeTypedAnswer  QuestionType = "typed_answer"  // free text matched against accepted answers
+	QuestionTypeNumeric      QuestionType = "numeric"       // closest guess to a target number
+	QuestionTypeOrdering     QuestionType = "ordering"      // arrange options into the correct sequence
+	QuestionTypePoll         QuestionType = "poll"          // unscored vote, no correct option
+	QuestionTypeBuzzer       QuestionType = "buzzer"        // first to buzz answers; the host judges
+)
+
 type Question struct {
-	ID        uuid.UUID `json:"id" db:"id"`
-	QuizID    uuid.UUID `json:"quiz_id" db:"quiz_id"`
-	Text      string    `json:"text" db:"text"`
-	TimeLimit int       `json:"time_limit" db:"time_limit"` // seconds
-	Order     int       `json:"order" db:"order"`
-	Options   []Option  `json:"options,omitempty"`
+	ID               uuid.UUID    `json:"id" db:"id"`
+	QuizID           *uuid.UUID   `json:"quiz_id" db:"quiz_id"` // nil for question bank entries
+	Type             QuestionType `json:"type" db:"type"`
+	Text             string       `json:"text" db:"text"`
+	TimeLimit        int          `json:"time_limit" db:"time_limit"` // seconds
+	Order            int          `json:"order" db:"order"`
+	PartialCredit    bool         `json:"partial_credit" db:"partial_credit"`               // multi_select and ordering
+	ScoringStrategy  string       `json:"scoring_strategy,omitempty" db:"scoring_strategy"` // overrides the quiz's strategy
+	PointsMultiplier int          `json:"points_multiplier" db:"points_multiplier"`         // 0, 1 or 2
+	Options          []Option     `json:"options,omitempty"`
+
+	// typed_answer only: accepted answers and how player input is matched against them.
+	AcceptedAnswers  []string `json:"accepted_answers,omitempty" db:"accepted_answers"`
+	CaseSensitive    bool     `json:"case_sensitive" db:"case_sensitive"`
+	IgnoreDiacritics bool     `json:"ignore_diacritics" db:"ignore_diacritics"`
+	MaxDistance      int      `json:"max_distance" db:"max_distance"` // Levenshtein edits tolerated
+
+	// numeric only: guesses within NumericTolerance of NumericTarget count as correct.
+	NumericTarget    *float64 `json:"numeric_target,omitempty" db:"numeric_target"`
+	NumericTolerance float64  `json:"numeric_tolerance" db:"numeric_tolerance"`
+
+	Tags       []string   `json:"tags" db:"tags"`
+	Difficulty Difficulty `json:"difficulty,omitempty" db:"difficulty"`
+
+	// Wager opens the question with a wagering phase: players stake part of
+	// their score instead of earning speed points.
+	Wager bool `json:"wager" db:"wager"`
+
+	// Reserve questions are only asked to settle podium ties in sudden death.
+	Reserve bool `json:"reserve" db:"reserve"`
+}
+
+// Difficulty grades a question for drawing from the question bank.
+type Difficulty string
+
+const (
+	DifficultyEasy   Difficulty = "easy"
+	DifficultyMedium Difficulty = "medium"
+	DifficultyHard   Difficulty = "hard"
+)
+
+// DrawRule draws Count random question bank entries into a quiz when a
+// session starts. An empty Tag or Difficulty matches any.
+type DrawRule struct {
+	Tag        string     `json:"tag,omitempty" db:"tag"`
+	Difficulty Difficulty `json:"difficulty,omitempty" db:"difficulty"`
+	Count      int        `json:"count" db:"count"`
 }
 
 type Option struct {
@@ -39,6 +124,7 @@ type Option struct {
 	QuestionID uuid.UUID `json:"question_id" db:"question_id"`
 	Text       string    `json:"text" db:"text"`
 	IsCorrect  bool      `json:"is_correct" db:"is_correct"`
+	Position   int       `json:"position" db:"position"` // correct place for ordering questions
 }
 
 // Game session
@@ -51,6 +137,42 @@ const (
 	GameStatusFinished GameStatus = "finished"
 )
 
+// SessionMode is how players move through a session's questions.
+type SessionMode string
+
+const (
+	SessionModeLive      SessionMode = "live"       // everyone on the same question, paced by the host
+	SessionModeSelfPaced SessionMode = "self_paced" // each player at their own pace until ClosesAt
+)
+
+// EliminationMode is how players are knocked out of a session.
+type EliminationMode string
+
+const (
+	EliminationOff         EliminationMode = "off"
+	EliminationWrongAnswer EliminationMode = "wrong_answer" // a wrong or missing answer eliminates
+	EliminationBottom      EliminationMode = "bottom"       // lowest EliminationCount scorers go after each question
+	EliminationLives       EliminationMode = "lives"        // a wrong or missing answer costs a life; out with none left
+)
+
+// TeamAssignment is how players end up on teams.
+type TeamAssignment string
+
+const (
+	TeamAssignmentNone   TeamAssignment = "none"   // individual play
+	TeamAssignmentChoose TeamAssignment = "choose" // players pick a team; balanced if they don't
+	TeamAssignmentAuto   TeamAssignment = "auto"   // always balanced by the server
+)
+
+// TeamScoring is how member scores combine into a team score.
+type TeamScoring string
+
+const (
+	TeamScoringSum     TeamScoring = "sum"
+	TeamScoringAverage TeamScoring = "average"
+	TeamScoringBest    TeamScoring = "best"
+)
+
 type GameSession struct {
 	ID        uuid.UUID  `json:"id" db:"id"`
 	QuizID    uuid.UUID  `json:"quiz_id" db:"quiz_id"`
@@ -59,32 +181,115 @@ type GameSession struct {
 	StartedAt *time.Time `json:"started_at,omitempty" db:"started_at"`
 	EndedAt   *time.Time `json:"ended_at,omitempty" db:"ended_at"`
 	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	// AutoAdvance runs the game without a host: the leaderboard moves on to
+	// the next question (or the podium) after AutoAdvanceDelay seconds.
+	AutoAdvance      bool `json:"auto_advance" db:"auto_advance"`
+	AutoAdvanceDelay int  `json:"auto_advance_delay" db:"auto_advance_delay"`
+
+	Mode     SessionMode `json:"mode" db:"mode"`
+	ClosesAt *time.Time  `json:"closes_at,omitempty" db:"closes_at"` // self-paced only
+
+	TeamAssignment TeamAssignment `json:"team_assignment" db:"team_assignment"`
+	TeamScoring    TeamScoring    `json:"team_scoring" db:"team_scoring"`
+	Teams          []GameTeam     `json:"teams,omitempty"`
+
+	Elimination      EliminationMode `json:"elimination" db:"elimination"`
+	EliminationCount int             `json:"elimination_count" db:"elimination_count"`
+	Lives            int             `json:"lives" db:"lives"` // lives mode: lives each player starts with
+
+	// Overrides for the quiz's shuffle settings; nil means use the quiz's.
+	ShuffleQuestions *bool          `json:"shuffle_questions,omitempty" db:"shuffle_questions"`
+	ShuffleOptions   *OptionShuffle `json:"shuffle_options,omitempty" db:"shuffle_options"`
+	QuestionCount    *int           `json:"question_count,omitempty" db:"question_count"`
+
+	Rounds []SessionRound `json:"rounds,omitempty"` // empty = one round of the session's quiz
 }
 
-type GamePlayer struct {
+// SessionRound is one round of a multi-round session.
+type SessionRound struct {
+	Position         int        `json:"position" db:"position"`
+	Title            string     `json:"title" db:"title"`
+	QuizID           *uuid.UUID `json:"quiz_id,omitempty" db:"quiz_id"`               // nil = the session's quiz
+	QuestionCount    *int       `json:"question_count,omitempty" db:"question_count"` // nil or 0 = all
+	ScoringStrategy  *string    `json:"scoring_strategy,omitempty" db:"scoring_strategy"`
+	PointsMultiplier *int       `json:"points_multiplier,omitempty" db:"points_multiplier"` // applied on top of each question's; nil = 1
+}
+
+type GameTeam struct {
 	ID        uuid.UUID `json:"id" db:"id"`
 	SessionID uuid.UUID `json:"session_id" db:"session_id"`
 	Name      string    `json:"name" db:"name"`
-	Score     int       `json:"score" db:"score"`
-	JoinedAt  time.Time `json:"joined_at" db:"joined_at"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
 }
 
-type GameAnswer struct {
+type GamePlayer struct {
 	ID         uuid.UUID `json:"id" db:"id"`
 	SessionID  uuid.UUID `json:"session_id" db:"session_id"`
-	PlayerID   uuid.UUID `json:"player_id" db:"player_id"`
-	QuestionID uuid.UUID `json:"question_id" db:"question_id"`
-	OptionID   uuid.UUID `json:"option_id" db:"option_id"`
-	AnsweredAt time.Time `json:"answered_at" db:"answered_at"`
-	IsCorrect  bool      `json:"is_correct" db:"is_correct"`
-	Points     int       `json:"points" db:"points"`
+	Name       string    `json:"name" db:"name"`
+	Score      int       `json:"score" db:"score"`
+	Streak     int       `json:"streak" db:"streak"`
+	BestStreak int       `json:"best_streak" db:"best_streak"`
+	JoinedAt   time.Time `json:"joined_at" db:"joined_at"`
+
+	// Self-paced progress.
+	Answered     int        `json:"answered" db:"answered"`
+	AnswerTimeMs int64      `json:"answer_time_ms" db:"answer_time_ms"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty" db:"finished_at"`
+
+	TeamID *uuid.UUID `json:"team_id,omitempty" db:"team_id"`
+
+	EliminatedAt *time.Time `json:"eliminated_at,omitempty" db:"eliminated_at"`
+	EliminatedOn *int       `json:"eliminated_on,omitempty" db:"eliminated_on"` // question index
+	Lives        *int       `json:"lives,omitempty" db:"lives"`                 // lives mode: lives left
+}
+
+type GameAnswer struct {
+	ID            uuid.UUID   `json:"id" db:"id"`
+	SessionID     uuid.UUID   `json:"session_id" db:"session_id"`
+	PlayerID      uuid.UUID   `json:"player_id" db:"player_id"`
+	QuestionID    uuid.UUID   `json:"question_id" db:"question_id"`
+	OptionID      *uuid.UUID  `json:"option_id,omitempty" db:"option_id"`           // single_choice
+	OptionIDs     []uuid.UUID `json:"option_ids,omitempty" db:"option_ids"`         // multi_select, ordering
+	TextAnswer    *string     `json:"text_answer,omitempty" db:"text_answer"`       // typed_answer
+	NumericAnswer *float64    `json:"numeric_answer,omitempty" db:"numeric_answer"` // numeric
+	AnsweredAt    time.Time   `json:"answered_at" db:"answered_at"`
+	IsCorrect     bool        `json:"is_correct" db:"is_correct"`
+	Points        int         `json:"points" db:"points"` // includes StreakBonus
+	Streak        int         `json:"streak" db:"streak"`
+	StreakBonus   int         `json:"streak_bonus" db:"streak_bonus"`
+	OptionOrder   []uuid.UUID `json:"option_order,omitempty" db:"option_order"` // options as this player saw them
+	Wager         *int        `json:"wager,omitempty" db:"wager"`               // amount staked on a wager question
+	AnswerMs      *int        `json:"answer_ms,omitempty" db:"answer_ms"`       // time taken to answer
 }
 
 // Leaderboard
 
 type LeaderboardEntry struct {
+	PlayerID uuid.UUID  `json:"player_id"`
+	Name     string     `json:"name"`
+	Score    int        `json:"score"`
+	Rank     int        `json:"rank"`
+	Streak   int        `json:"streak"`
+	Finished bool       `json:"finished,omitempty"` // self-paced: player has answered every question
+	TeamID   *uuid.UUID `json:"team_id,omitempty"`
+	TeamName string     `json:"team_name,omitempty"`
+	// Eliminated players stay on the board as spectators.
+	Eliminated bool `json:"eliminated,omitempty"`
+	Lives      *int `json:"lives,omitempty"` // lives mode: lives left
+}
+
+// RoundStandingEntry is a player's result for a single round.
+type RoundStandingEntry struct {
 	PlayerID uuid.UUID `json:"player_id"`
 	Name     string    `json:"name"`
-	Score    int       `json:"score"`
+	Points   int       `json:"points"`
 	Rank     int       `json:"rank"`
 }
+
+type TeamLeaderboardEntry struct {
+	TeamID  uuid.UUID `json:"team_id"`
+	Name    string    `json:"name"`
+	Score   int       `json:"score"`
+	Rank    int       `json:"rank"`
+	Players int       `json:"players"`
+}
@@ -0,0 +1,10 @@
+DELETE FROM game_answers WHERE option_id IS NULL;
+
+ALTER TABLE game_answers
+    DROP COLUMN IF EXISTS option_ids,
+    ALTER COLUMN option_id SET NOT NULL;
+
+ALTER TABLE questions
+    DROP CONSTRAINT IF EXISTS questions_type_check,
+    DROP COLUMN IF EXISTS partial_credit,
+    DROP COLUMN IF EXISTS type;
@@ -0,0 +1,10 @@
+ALTER TABLE questions
+    ADD COLUMN type           TEXT    NOT NULL DEFAULT 'single_choice',
+    ADD COLUMN partial_credit BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD CONSTRAINT questions_type_check CHECK (type IN ('single_choice', 'multi_select'));
+
+-- Multi-select answers record the full selection in option_ids; option_id is
+-- only set for single-choice answers.
+ALTER TABLE game_answers
+    ALTER COLUMN option_id DROP NOT NULL,
+    ADD COLUMN option_ids UUID[];
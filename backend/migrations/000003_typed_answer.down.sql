@@ -0,0 +1,13 @@
+DELETE FROM game_answers WHERE text_answer IS NOT NULL;
+DELETE FROM questions WHERE type = 'typed_answer';
+
+ALTER TABLE game_answers
+    DROP COLUMN IF EXISTS text_answer;
+
+ALTER TABLE questions
+    DROP CONSTRAINT questions_type_check,
+    ADD CONSTRAINT questions_type_check CHECK (type IN ('single_choice', 'multi_select')),
+    DROP COLUMN IF EXISTS max_distance,
+    DROP COLUMN IF EXISTS ignore_diacritics,
+    DROP COLUMN IF EXISTS case_sensitive,
+    DROP COLUMN IF EXISTS accepted_answers;
@@ -0,0 +1,11 @@
+ALTER TABLE questions
+    ADD COLUMN accepted_answers  TEXT[]  NOT NULL DEFAULT '{}',
+    ADD COLUMN case_sensitive    BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN ignore_diacritics BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN max_distance      INT     NOT NULL DEFAULT 0,
+    DROP CONSTRAINT questions_type_check,
+    ADD CONSTRAINT questions_type_check CHECK (type IN ('single_choice', 'multi_select', 'typed_answer'));
+
+-- Typed answers store exactly what the player entered.
+ALTER TABLE game_answers
+    ADD COLUMN text_answer TEXT;
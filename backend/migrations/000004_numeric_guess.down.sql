@@ -0,0 +1,11 @@
+DELETE FROM game_answers WHERE numeric_answer IS NOT NULL;
+DELETE FROM questions WHERE type = 'numeric';
+
+ALTER TABLE game_answers
+    DROP COLUMN IF EXISTS numeric_answer;
+
+ALTER TABLE questions
+    DROP CONSTRAINT questions_type_check,
+    ADD CONSTRAINT questions_type_check CHECK (type IN ('single_choice', 'multi_select', 'typed_answer')),
+    DROP COLUMN IF EXISTS numeric_tolerance,
+    DROP COLUMN IF EXISTS numeric_target;
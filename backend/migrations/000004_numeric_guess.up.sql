@@ -0,0 +1,8 @@
+ALTER TABLE questions
+    ADD COLUMN numeric_target    DOUBLE PRECISION,
+    ADD COLUMN numeric_tolerance DOUBLE PRECISION NOT NULL DEFAULT 0,
+    DROP CONSTRAINT questions_type_check,
+    ADD CONSTRAINT questions_type_check CHECK (type IN ('single_choice', 'multi_select', 'typed_answer', 'numeric'));
+
+ALTER TABLE game_answers
+    ADD COLUMN numeric_answer DOUBLE PRECISION;
@@ -0,0 +1,8 @@
+DELETE FROM questions WHERE type = 'ordering';
+
+ALTER TABLE questions
+    DROP CONSTRAINT questions_type_check,
+    ADD CONSTRAINT questions_type_check CHECK (type IN ('single_choice', 'multi_select', 'typed_answer', 'numeric'));
+
+ALTER TABLE options
+    DROP COLUMN IF EXISTS position;
@@ -0,0 +1,8 @@
+-- position is the option's place in the author's list. For ordering questions
+-- it is the correct sequence.
+ALTER TABLE options
+    ADD COLUMN position INT NOT NULL DEFAULT 0;
+
+ALTER TABLE questions
+    DROP CONSTRAINT questions_type_check,
+    ADD CONSTRAINT questions_type_check CHECK (type IN ('single_choice', 'multi_select', 'typed_answer', 'numeric', 'ordering'));
@@ -0,0 +1,10 @@
+ALTER TABLE game_answers
+    DROP COLUMN IF EXISTS streak_bonus,
+    DROP COLUMN IF EXISTS streak;
+
+ALTER TABLE game_players
+    DROP COLUMN IF EXISTS best_streak,
+    DROP COLUMN IF EXISTS streak;
+
+ALTER TABLE quizzes
+    DROP COLUMN IF EXISTS streak_schedule;
@@ -0,0 +1,12 @@
+-- streak_schedule[n] is the bonus for the (n+1)th consecutive correct answer;
+-- the last entry repeats. Empty means the engine default.
+ALTER TABLE quizzes
+    ADD COLUMN streak_schedule INT[] NOT NULL DEFAULT '{}';
+
+ALTER TABLE game_players
+    ADD COLUMN streak      INT NOT NULL DEFAULT 0,
+    ADD COLUMN best_streak INT NOT NULL DEFAULT 0;
+
+ALTER TABLE game_answers
+    ADD COLUMN streak       INT NOT NULL DEFAULT 0,
+    ADD COLUMN streak_bonus INT NOT NULL DEFAULT 0;
@@ -0,0 +1,5 @@
+ALTER TABLE questions
+    DROP COLUMN IF EXISTS scoring_strategy;
+
+ALTER TABLE quizzes
+    DROP COLUMN IF EXISTS scoring_strategy;
@@ -0,0 +1,6 @@
+ALTER TABLE quizzes
+    ADD COLUMN scoring_strategy TEXT NOT NULL DEFAULT 'linear';
+
+-- NULL means the question uses the quiz's strategy.
+ALTER TABLE questions
+    ADD COLUMN scoring_strategy TEXT;
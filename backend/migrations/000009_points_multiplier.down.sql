@@ -0,0 +1,3 @@
+ALTER TABLE questions
+    DROP CONSTRAINT IF EXISTS questions_points_multiplier_check,
+    DROP COLUMN IF EXISTS points_multiplier;
@@ -0,0 +1,3 @@
+ALTER TABLE questions
+    ADD COLUMN points_multiplier INT NOT NULL DEFAULT 1,
+    ADD CONSTRAINT questions_points_multiplier_check CHECK (points_multiplier IN (0, 1, 2));
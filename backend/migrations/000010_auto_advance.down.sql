@@ -0,0 +1,3 @@
+ALTER TABLE game_sessions
+    DROP COLUMN IF EXISTS auto_advance_delay,
+    DROP COLUMN IF EXISTS auto_advance;
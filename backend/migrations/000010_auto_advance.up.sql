@@ -0,0 +1,3 @@
+ALTER TABLE game_sessions
+    ADD COLUMN auto_advance       BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN auto_advance_delay INT     NOT NULL DEFAULT 8 CHECK (auto_advance_delay BETWEEN 3 AND 120);
@@ -0,0 +1,8 @@
+ALTER TABLE game_players
+    DROP COLUMN IF EXISTS finished_at,
+    DROP COLUMN IF EXISTS answer_time_ms,
+    DROP COLUMN IF EXISTS answered;
+
+ALTER TABLE game_sessions
+    DROP COLUMN IF EXISTS closes_at,
+    DROP COLUMN IF EXISTS mode;
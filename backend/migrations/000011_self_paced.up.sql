@@ -0,0 +1,10 @@
+ALTER TABLE game_sessions
+    ADD COLUMN mode      TEXT NOT NULL DEFAULT 'live' CHECK (mode IN ('live', 'self_paced')),
+    ADD COLUMN closes_at TIMESTAMPTZ;
+
+-- Self-paced players finish at different times; answer_time_ms (total time
+-- spent answering) breaks score ties between them.
+ALTER TABLE game_players
+    ADD COLUMN answered       INT    NOT NULL DEFAULT 0,
+    ADD COLUMN answer_time_ms BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN finished_at    TIMESTAMPTZ;
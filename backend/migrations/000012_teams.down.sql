@@ -0,0 +1,8 @@
+ALTER TABLE game_players
+    DROP COLUMN IF EXISTS team_id;
+
+ALTER TABLE game_sessions
+    DROP COLUMN IF EXISTS team_scoring,
+    DROP COLUMN IF EXISTS team_assignment;
+
+DROP TABLE IF EXISTS game_teams;
@@ -0,0 +1,18 @@
+CREATE TABLE game_teams (
+    id         UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    session_id UUID NOT NULL REFERENCES game_sessions(id) ON DELETE CASCADE,
+    name       TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (session_id, name)
+);
+
+CREATE INDEX idx_game_teams_session ON game_teams(session_id);
+
+-- team_assignment: none (no teams), choose (players pick, else balanced) or
+-- auto (always balanced). team_scoring: how member scores combine.
+ALTER TABLE game_sessions
+    ADD COLUMN team_assignment TEXT NOT NULL DEFAULT 'none' CHECK (team_assignment IN ('none', 'choose', 'auto')),
+    ADD COLUMN team_scoring    TEXT NOT NULL DEFAULT 'sum' CHECK (team_scoring IN ('sum', 'average', 'best'));
+
+ALTER TABLE game_players
+    ADD COLUMN team_id UUID REFERENCES game_teams(id) ON DELETE SET NULL;
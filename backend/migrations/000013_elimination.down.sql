@@ -0,0 +1,7 @@
+ALTER TABLE game_players
+    DROP COLUMN IF EXISTS eliminated_on,
+    DROP COLUMN IF EXISTS eliminated_at;
+
+ALTER TABLE game_sessions
+    DROP COLUMN IF EXISTS elimination_count,
+    DROP COLUMN IF EXISTS elimination;
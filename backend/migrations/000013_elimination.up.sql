@@ -0,0 +1,10 @@
+-- elimination: off, wrong_answer (a wrong or missing answer eliminates) or
+-- bottom (the elimination_count lowest scorers go after each question).
+ALTER TABLE game_sessions
+    ADD COLUMN elimination       TEXT NOT NULL DEFAULT 'off' CHECK (elimination IN ('off', 'wrong_answer', 'bottom')),
+    ADD COLUMN elimination_count INT  NOT NULL DEFAULT 1 CHECK (elimination_count BETWEEN 1 AND 50);
+
+-- eliminated_on is the question index the player went out on.
+ALTER TABLE game_players
+    ADD COLUMN eliminated_at TIMESTAMPTZ,
+    ADD COLUMN eliminated_on INT;
@@ -0,0 +1,14 @@
+ALTER TABLE game_answers
+    DROP COLUMN IF EXISTS option_order;
+
+DROP TABLE IF EXISTS game_session_questions;
+
+ALTER TABLE game_sessions
+    DROP COLUMN IF EXISTS question_count,
+    DROP COLUMN IF EXISTS shuffle_options,
+    DROP COLUMN IF EXISTS shuffle_questions;
+
+ALTER TABLE quizzes
+    DROP COLUMN IF EXISTS question_count,
+    DROP COLUMN IF EXISTS shuffle_options,
+    DROP COLUMN IF EXISTS shuffle_questions;
@@ -0,0 +1,23 @@
+ALTER TABLE quizzes
+    ADD COLUMN shuffle_questions BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN shuffle_options   TEXT    NOT NULL DEFAULT 'none' CHECK (shuffle_options IN ('none', 'session', 'player')),
+    ADD COLUMN question_count    INT     CHECK (question_count >= 0); -- draw this many; NULL or 0 = all
+
+-- Per-session overrides; NULL means use the quiz's setting.
+ALTER TABLE game_sessions
+    ADD COLUMN shuffle_questions BOOLEAN,
+    ADD COLUMN shuffle_options   TEXT CHECK (shuffle_options IN ('none', 'session', 'player')),
+    ADD COLUMN question_count    INT  CHECK (question_count >= 0);
+
+-- The questions a session actually used, in the order they were asked, with
+-- the option order shown to everyone (per-player orders are on game_answers).
+CREATE TABLE game_session_questions (
+    session_id   UUID NOT NULL REFERENCES game_sessions(id) ON DELETE CASCADE,
+    position     INT  NOT NULL,
+    question_id  UUID NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    option_order UUID[] NOT NULL DEFAULT '{}',
+    PRIMARY KEY (session_id, position)
+);
+
+ALTER TABLE game_answers
+    ADD COLUMN option_order UUID[];
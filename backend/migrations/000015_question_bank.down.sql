@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS quiz_draw_rules;
+
+DROP INDEX IF EXISTS idx_questions_tags;
+DROP INDEX IF EXISTS idx_questions_bank;
+
+-- Bank questions have no quiz to belong to once quiz_id is required again.
+DELETE FROM questions WHERE quiz_id IS NULL;
+
+ALTER TABLE questions
+    DROP CONSTRAINT IF EXISTS questions_owner_check,
+    DROP COLUMN IF EXISTS difficulty,
+    DROP COLUMN IF EXISTS tags,
+    DROP COLUMN IF EXISTS admin_id,
+    ALTER COLUMN quiz_id SET NOT NULL;
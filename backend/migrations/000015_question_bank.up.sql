@@ -0,0 +1,24 @@
+-- Bank questions belong to an admin rather than a quiz (quiz_id IS NULL) and
+-- are drawn into quizzes at session start by quiz_draw_rules.
+ALTER TABLE questions
+    ALTER COLUMN quiz_id DROP NOT NULL,
+    ADD COLUMN admin_id   UUID REFERENCES admins(id) ON DELETE CASCADE,
+    ADD COLUMN tags       TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN difficulty TEXT CHECK (difficulty IN ('easy', 'medium', 'hard')),
+    ADD CONSTRAINT questions_owner_check CHECK (quiz_id IS NOT NULL OR admin_id IS NOT NULL);
+
+CREATE INDEX idx_questions_bank ON questions(admin_id) WHERE quiz_id IS NULL;
+CREATE INDEX idx_questions_tags ON questions USING GIN (tags);
+
+-- A rule draws count random bank questions matching tag and difficulty
+-- (either may be NULL to match any).
+CREATE TABLE quiz_draw_rules (
+    id         UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    quiz_id    UUID NOT NULL REFERENCES quizzes(id) ON DELETE CASCADE,
+    position   INT  NOT NULL,
+    tag        TEXT,
+    difficulty TEXT CHECK (difficulty IN ('easy', 'medium', 'hard')),
+    count      INT  NOT NULL CHECK (count BETWEEN 1 AND 100)
+);
+
+CREATE INDEX idx_quiz_draw_rules_quiz ON quiz_draw_rules(quiz_id);
@@ -0,0 +1,8 @@
+ALTER TABLE game_answers
+    DROP COLUMN IF EXISTS wager;
+
+ALTER TABLE quizzes
+    DROP COLUMN IF EXISTS negative_scores;
+
+ALTER TABLE questions
+    DROP COLUMN IF EXISTS wager;
@@ -0,0 +1,13 @@
+-- A wager question opens with a wagering phase: players stake part of their
+-- score, winning it on a correct answer and losing it otherwise.
+ALTER TABLE questions
+    ADD COLUMN wager BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- negative_scores lets lost wagers take a score below zero; otherwise scores
+-- are floored at zero.
+ALTER TABLE quizzes
+    ADD COLUMN negative_scores BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- wager is the amount staked, NULL for unwagered questions.
+ALTER TABLE game_answers
+    ADD COLUMN wager INT;
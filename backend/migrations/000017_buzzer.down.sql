@@ -0,0 +1,5 @@
+DELETE FROM questions WHERE type = 'buzzer';
+
+ALTER TABLE questions
+    DROP CONSTRAINT questions_type_check,
+    ADD CONSTRAINT questions_type_check CHECK (type IN ('single_choice', 'multi_select', 'typed_answer', 'numeric', 'ordering', 'poll'));
@@ -0,0 +1,5 @@
+-- buzzer: the first player to buzz answers aloud or in text and the host
+-- judges it. accepted_answers, if any, are shown to the host for reference.
+ALTER TABLE questions
+    DROP CONSTRAINT questions_type_check,
+    ADD CONSTRAINT questions_type_check CHECK (type IN ('single_choice', 'multi_select', 'typed_answer', 'numeric', 'ordering', 'poll', 'buzzer'));
@@ -0,0 +1,9 @@
+ALTER TABLE game_players
+    DROP COLUMN IF EXISTS lives;
+
+UPDATE game_sessions SET elimination = 'wrong_answer' WHERE elimination = 'lives';
+
+ALTER TABLE game_sessions
+    DROP COLUMN IF EXISTS lives,
+    DROP CONSTRAINT game_sessions_elimination_check,
+    ADD CONSTRAINT game_sessions_elimination_check CHECK (elimination IN ('off', 'wrong_answer', 'bottom'));
@@ -0,0 +1,10 @@
+-- lives: each wrong or missing answer costs a life and players with none left
+-- are eliminated. lives is the number each player starts with.
+ALTER TABLE game_sessions
+    DROP CONSTRAINT game_sessions_elimination_check,
+    ADD CONSTRAINT game_sessions_elimination_check CHECK (elimination IN ('off', 'wrong_answer', 'bottom', 'lives')),
+    ADD COLUMN lives INT NOT NULL DEFAULT 3 CHECK (lives BETWEEN 1 AND 10);
+
+-- lives is what the player has left; NULL outside lives sessions.
+ALTER TABLE game_players
+    ADD COLUMN lives INT;
@@ -0,0 +1,4 @@
+ALTER TABLE game_session_questions
+    DROP COLUMN IF EXISTS round;
+
+DROP TABLE IF EXISTS game_session_rounds;
@@ -0,0 +1,17 @@
+-- A session with rounds plays them in position order, pausing for an
+-- intermission between them. Each round asks questions from its own quiz
+-- (NULL = the session's quiz) and may override scoring for its questions.
+CREATE TABLE game_session_rounds (
+    session_id        UUID NOT NULL REFERENCES game_sessions(id) ON DELETE CASCADE,
+    position          INT  NOT NULL,
+    title             TEXT NOT NULL,
+    quiz_id           UUID REFERENCES quizzes(id) ON DELETE CASCADE,
+    question_count    INT  CHECK (question_count >= 0),                -- draw this many; NULL or 0 = all
+    scoring_strategy  TEXT,                                            -- NULL = the quiz's
+    points_multiplier INT  NOT NULL DEFAULT 1 CHECK (points_multiplier IN (0, 1, 2)),
+    PRIMARY KEY (session_id, position)
+);
+
+-- round is the position of the round each question was asked in.
+ALTER TABLE game_session_questions
+    ADD COLUMN round INT NOT NULL DEFAULT 0;
@@ -0,0 +1,12 @@
+ALTER TABLE game_players
+    DROP COLUMN IF EXISTS tiebreak;
+
+ALTER TABLE game_answers
+    DROP COLUMN IF EXISTS answer_ms;
+
+DELETE FROM questions WHERE reserve;
+ALTER TABLE questions
+    DROP COLUMN IF EXISTS reserve;
+
+ALTER TABLE quizzes
+    DROP COLUMN IF EXISTS tie_breaker;
@@ -0,0 +1,18 @@
+-- tie_breaker ranks players level on points: time (less total answer time,
+-- then the earliest correct answer, goes first), shared (they share a rank)
+-- or sudden_death (shared, but ties on the podium are settled with the
+-- quiz's reserve questions).
+ALTER TABLE quizzes
+    ADD COLUMN tie_breaker TEXT NOT NULL DEFAULT 'time' CHECK (tie_breaker IN ('time', 'shared', 'sudden_death'));
+
+-- Reserve questions are kept out of normal play and only asked in sudden death.
+ALTER TABLE questions
+    ADD COLUMN reserve BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- answer_ms is how long the player took to answer.
+ALTER TABLE game_answers
+    ADD COLUMN answer_ms INT;
+
+-- tiebreak orders players level on points after sudden death; higher ranks first.
+ALTER TABLE game_players
+    ADD COLUMN tiebreak INT NOT NULL DEFAULT 0;